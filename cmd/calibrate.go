@@ -0,0 +1,166 @@
+/*
+Copyright © 2025 Aris Tzermias
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tzermias/deskctl/pkg/jiecang"
+)
+
+var (
+	actualHeight     float64
+	clearCalibration bool
+)
+
+// calibrateCmd represents the calibrate command
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "Calibrates the heights of the desk to a tape measure",
+	Long: `Measures the difference between the height of the desk surface, given by
+	--actual, and the height reported by the controller, and saves it for the
+	desk. Every height displayed or requested by the other commands is then the
+	real height of the desk surface.
+
+	--actual is in the units selected with --units. Use --clear to remove the
+	calibration.`,
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if clearCalibration {
+			return
+		}
+		if actualHeight <= 0 {
+			fmt.Fprintln(os.Stderr, "--actual must be set to a positive value")
+			os.Exit(1)
+		}
+
+		//Initialize device
+		var err error
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if clearCalibration {
+			path, err := saveCalibration(0)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to clear calibration: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Calibration cleared in %s\n", path)
+			return
+		}
+
+		opCtx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		u := displayUnit(j)
+		actual, err := jiecang.ParseHeight(actualHeight, u)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid height: %v\n", err)
+			os.Exit(1)
+		}
+		offset, err := j.CalibrationOffset(opCtx, actual)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read height: %v\n", err)
+			os.Exit(1)
+		}
+		path, err := saveCalibration(offset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save calibration: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Calibration offset: %s\n", formatOffset(offset, u))
+		fmt.Printf("Calibration saved to %s\n", path)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if j == nil {
+			return
+		}
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// formatOffset formats offset, in millimeters, in u with its sign (e.g
+// "+1.5 cm").
+func formatOffset(offset int, u jiecang.Unit) string {
+	if offset < 0 {
+		return "-" + jiecang.Height(-offset).Format(u)
+	}
+	return "+" + jiecang.Height(offset).Format(u)
+}
+
+// calibration is the height calibration of a desk.
+type calibration struct {
+	Offset int `json:"offset_mm"` // Added to the heights reported by the controller
+}
+
+// calibrationPath returns the path of the calibration of the desk selected
+// by the global flags, named like its capability profile.
+func calibrationPath() (string, error) {
+	profile, err := profilePath()
+	if err != nil {
+		return "", err
+	}
+	// deskctl/profiles/<desk>.json -> deskctl/calibrations/<desk>.json
+	return filepath.Join(filepath.Dir(filepath.Dir(profile)), "calibrations", filepath.Base(profile)), nil
+}
+
+// loadCalibration returns the calibration offset of the desk selected by
+// the global flags in millimeters, 0 if the desk was never calibrated.
+func loadCalibration() (int, error) {
+	path, err := calibrationPath()
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var c calibration
+	if err := json.Unmarshal(data, &c); err != nil {
+		return 0, fmt.Errorf("invalid calibration %s: %w", path, err)
+	}
+	return c.Offset, nil
+}
+
+// saveCalibration saves offset as the calibration offset of the desk
+// selected by the global flags and returns its path.
+func saveCalibration(offset int) (string, error) {
+	path, err := calibrationPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(calibration{Offset: offset}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func init() {
+	rootCmd.AddCommand(calibrateCmd)
+	calibrateCmd.Flags().Float64Var(&actualHeight, "actual", 0, "Height of the desk surface measured with a tape")
+	calibrateCmd.Flags().BoolVar(&clearCalibration, "clear", false, "Remove the calibration of the desk")
+	calibrateCmd.MarkFlagsMutuallyExclusive("actual", "clear")
+}
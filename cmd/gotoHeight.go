@@ -8,14 +8,14 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tzermias/deskctl/pkg/jiecang"
-	"tinygo.org/x/bluetooth"
 )
 
-var height int
+var target jiecang.Target
 
 // gotoHeightCmd represents the gotoHeight command
 var gotoHeightCmd = &cobra.Command{
@@ -23,26 +23,39 @@ var gotoHeightCmd = &cobra.Command{
 	Short: "Sets height of desk to HEIGHT",
 	Long: `Moves the desk up or down to reach height specified by HEIGHT.
 
-	An error is thrown if HEIGHT exceeeds limits of the desk.`,
-	Args: cobra.ExactArgs(1),
+	HEIGHT is in the units selected with --units, which default to the units
+	setting of the controller (centimeters or inches). Centimeters have
+	millimeter precision (e.g 107.5).
+	An error is thrown if HEIGHT exceeeds limits of the desk.
+
+	HEIGHT can also be relative to the current height (e.g +5 or -3.5) or a
+	percentage of the height range of the desk (e.g 75%). These targets are
+	clamped to the limits of the desk.`,
+	// Flags are parsed by parseFlagsWithNegatives, so that negative
+	// heights (e.g -3.5) are not taken for shorthand flags
+	DisableFlagParsing: true,
 	PreRun: func(cmd *cobra.Command, args []string) {
-		var err error
-		// Validate that argument is an integer
-		height, err = strconv.Atoi(args[0])
+		args, err := parseFlagsWithNegatives(cmd, args)
+		if help, _ := cmd.Flags().GetBool("help"); help && err == nil {
+			_ = cmd.Help()
+			os.Exit(0)
+		}
+		if err == nil {
+			err = cobra.ExactArgs(1)(cmd, args)
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid height value [%s]: %v\n", args[0], err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			_ = cmd.Usage()
 			os.Exit(1)
 		}
-		// Validate MAC address
-		var mac bluetooth.MAC
-		mac, err = bluetooth.ParseMAC(address)
+
+		target, err = jiecang.ParseTarget(args[0])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid MAC address [%s]: %v\n", address, err)
+			fmt.Fprintf(os.Stderr, "Invalid height value [%s]: %v\n", args[0], err)
 			os.Exit(1)
 		}
-
 		//Initialize device
-		j, err = jiecang.Init(adapter, bluetooth.Address{MACAddress: bluetooth.MACAddress{MAC: mac}})
+		j, err = initDesk()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
 			os.Exit(1)
@@ -55,10 +68,18 @@ var gotoHeightCmd = &cobra.Command{
 		opCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
 		defer cancel()
 
-		if err := j.GoToHeight(opCtx, uint8(height)); err != nil {
+		height, err := j.ResolveTarget(target, displayUnit(j))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid height value [%s]: %v\n", target, err)
+			os.Exit(1)
+		}
+		res, err := j.GoToHeight(opCtx, height)
+		progress.finish()
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to go to height: %v\n", err)
 			os.Exit(1)
 		}
+		checkMove(res)
 	},
 	PostRun: func(cmd *cobra.Command, args []string) {
 		if err := j.Disconnect(); err != nil {
@@ -68,6 +89,62 @@ var gotoHeightCmd = &cobra.Command{
 	},
 }
 
+// parseFlagsWithNegatives parses the flags of cmd, whose flag parsing is
+// disabled, and returns its positional arguments. Negative numbers (e.g
+// -3.5) are positional arguments rather than shorthand flags, unless they
+// are the value of the flag before them.
+func parseFlagsWithNegatives(cmd *cobra.Command, args []string) ([]string, error) {
+	// Merge the persistent flags of the parents into the flags of cmd
+	_ = cmd.InheritedFlags()
+	flags := cmd.Flags()
+
+	var numbers, rest []string
+	for i, arg := range args {
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		_, err := strconv.ParseFloat(arg, 64)
+		if err == nil && strings.HasPrefix(arg, "-") && (i == 0 || !takesValue(cmd, args[i-1])) {
+			numbers = append(numbers, arg)
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	if err := flags.Parse(rest); err != nil {
+		return nil, err
+	}
+	return append(numbers, flags.Args()...), nil
+}
+
+// takesValue returns whether arg is a flag of cmd followed by its value,
+// such as --units or -a.
+func takesValue(cmd *cobra.Command, arg string) bool {
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		f := cmd.Flags().Lookup(arg[2:])
+		return f != nil && f.NoOptDefVal == ""
+	case len(arg) == 2 && arg[0] == '-':
+		f := cmd.Flags().ShorthandLookup(arg[1:])
+		return f != nil && f.NoOptDefVal == ""
+	}
+	return false
+}
+
+// checkMove reports cancelled movements and exits with an error if the desk
+// stalled or a collision is suspected.
+func checkMove(res jiecang.MoveResult) {
+	u := displayUnit(j)
+	switch res.Outcome {
+	case jiecang.Cancelled:
+		fmt.Printf("Operation cancelled at height %s\n", res.Height.Format(u))
+	case jiecang.Stalled, jiecang.CollisionSuspected:
+		fmt.Fprintf(os.Stderr, "Desk stopped at %s before reaching %s: %s\n", res.Height.Format(u), res.Target.Format(u), res.Outcome)
+		os.Exit(1)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(gotoHeightCmd)
 
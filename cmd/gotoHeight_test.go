@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/sim"
+)
+
+// serveDesk serves desk over TCP like a serial bridge and returns the
+// address to pass to --address.
+func serveDesk(t *testing.T, desk *sim.Desk) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_ = desk.Subscribe(func(buf []byte) { _, _ = conn.Write(buf) })
+
+		// Hand the commands to the desk one frame at a time
+		var pending []byte
+		buf := make([]byte, 256)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			pending = append(pending, buf[:n]...)
+			for len(pending) >= 4 && len(pending) >= int(pending[3])+6 {
+				size := int(pending[3]) + 6
+				_ = desk.Write(pending[:size])
+				pending = pending[size:]
+			}
+		}
+	}()
+	return "tcp://" + l.Addr().String()
+}
+
+// runCommand runs deskctl with args.
+func runCommand(t *testing.T, args ...string) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	// Commands keep the context of their first execution otherwise
+	cmd, _, err := rootCmd.Find(args)
+	require.NoError(t, err)
+	cmd.SetContext(ctx)
+
+	rootCmd.SetArgs(args)
+	require.NoError(t, rootCmd.ExecuteContext(ctx))
+}
+
+func TestGotoHeight(t *testing.T) {
+	tests := []struct {
+		name     string   // Name of the testcase
+		args     []string // Arguments after goto-height, {address} is replaced with the desk address
+		expected int      // Expected height of the desk in millimeters
+	}{
+		{
+			name:     "Absolute height",
+			args:     []string{"--address", "{address}", "85"},
+			expected: 850,
+		},
+		{
+			name:     "Negative relative height",
+			args:     []string{"-3.5", "--address", "{address}"},
+			expected: 765,
+		},
+		{
+			name:     "Negative relative height after the flags",
+			args:     []string{"-a", "{address}", "--units", "cm", "-3.5"},
+			expected: 765,
+		},
+		{
+			name:     "Negative relative height after --",
+			args:     []string{"-a", "{address}", "--", "-3.5"},
+			expected: 765,
+		},
+		{
+			name:     "Positive relative height",
+			args:     []string{"+5", "-a", "{address}"},
+			expected: 850,
+		},
+		{
+			name:     "Percentage",
+			args:     []string{"0%", "-a", "{address}"},
+			expected: 620,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desk := sim.New(sim.Config{
+				Height:       800,
+				Speed:        200,
+				TickInterval: 10 * time.Millisecond,
+			})
+			address := serveDesk(t, desk)
+
+			args := []string{"goto-height"}
+			for _, arg := range test.args {
+				if arg == "{address}" {
+					arg = address
+				}
+				args = append(args, arg)
+			}
+			runCommand(t, args...)
+			assert.InDelta(t, test.expected, desk.Height(), 2)
+		})
+	}
+}
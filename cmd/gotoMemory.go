@@ -12,7 +12,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tzermias/deskctl/pkg/jiecang"
-	"tinygo.org/x/bluetooth"
 )
 
 var memoryNum int
@@ -21,27 +20,21 @@ var j *jiecang.Jiecang
 var gotoMemoryCmd = &cobra.Command{
 	Use:   "goto-memory [MEMORY]",
 	Short: "Moves the desk to memory",
-	Long:  `Moves the desk to the designated memory. [MEMORY] is between 1-3.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Moves the desk to the designated memory. [MEMORY] is between 1-3, or 1-4
+	on controllers with a fourth memory preset.`,
+	Args: cobra.ExactArgs(1),
 	PreRun: func(cmd *cobra.Command, args []string) {
 		var err error
-		// Validate that argument is an integer between 1 and 3
+		// Validate that argument is an integer between 1 and 4. Whether the
+		// controller has memory preset 4 is checked once connected.
 		memoryNum, err = strconv.Atoi(args[0])
-		if err != nil || memoryNum < 1 || memoryNum > 3 {
-			fmt.Fprintf(os.Stderr, "Memory number is not within boundaries (1-3): %d\n", memoryNum)
-			os.Exit(1)
-		}
-
-		// Validate MAC address
-		var mac bluetooth.MAC
-		mac, err = bluetooth.ParseMAC(address)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid MAC address [%s]: %v\n", address, err)
+		if err != nil || memoryNum < 1 || memoryNum > 4 {
+			fmt.Fprintf(os.Stderr, "Memory number is not within boundaries (1-4): %s\n", args[0])
 			os.Exit(1)
 		}
 
 		//Initialize device
-		j, err = jiecang.Init(adapter, bluetooth.Address{MACAddress: bluetooth.MACAddress{MAC: mac}})
+		j, err = initDesk()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
 			os.Exit(1)
@@ -54,10 +47,13 @@ var gotoMemoryCmd = &cobra.Command{
 		opCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
 		defer cancel()
 
-		if err := j.GoToMemory(opCtx, memoryNum); err != nil {
+		res, err := j.GoToMemory(opCtx, memoryNum)
+		progress.finish()
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to go to memory %d: %v\n", memoryNum, err)
 			os.Exit(1)
 		}
+		checkMove(res)
 	},
 	PostRun: func(cmd *cobra.Command, args []string) {
 		if err := j.Disconnect(); err != nil {
@@ -0,0 +1,170 @@
+/*
+Copyright © 2025 Aris Tzermias
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tzermias/deskctl/pkg/jiecang"
+)
+
+var (
+	limitMin float64
+	limitMax float64
+)
+
+// limitsCmd represents the limits command
+var limitsCmd = &cobra.Command{
+	Use:   "limits",
+	Short: "Shows the height range and the height limits of the desk",
+	Long: `Shows the physical height range of the desk and the user-defined limits
+	restricting it.
+
+	Use the subcommands to change the limits.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		//Initialize device
+		var err error
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		u := displayUnit(j)
+		fmt.Printf("Physical range: %s - %s\n", j.LowestHeight.Format(u), j.HighestHeight.Format(u))
+
+		limits, err := j.QueryLimits(cmd.Context())
+		if errors.Is(err, jiecang.ErrUnsupported) || errors.Is(err, jiecang.ErrTimeout) {
+			fmt.Println("Limits: not supported by the controller")
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to query limits: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Lower limit: %s\n", formatLimit(limits.Min, u))
+		fmt.Printf("Upper limit: %s\n", formatLimit(limits.Max, u))
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var limitsSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Sets the height limits of the desk",
+	Long: `Programs lower and/or upper height limits into the desk controller.
+
+	Limits are in the units selected with --units. A limit that is not given
+	is kept as it is. The controller sets limits at the current height, so the
+	desk MOVES to each limit in turn, including a kept one, and then back to
+	its initial height. Make sure nothing is in the way.`,
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if limitMin <= 0 && limitMax <= 0 {
+			fmt.Fprintln(os.Stderr, "At least one of --min and --max must be set to a positive value")
+			os.Exit(1)
+		}
+
+		//Initialize device
+		var err error
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		u := displayUnit(j)
+		var lower, upper jiecang.Height
+		var err error
+		if limitMin > 0 {
+			if lower, err = jiecang.ParseHeight(limitMin, u); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid lower limit: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if limitMax > 0 {
+			if upper, err = jiecang.ParseHeight(limitMax, u); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid upper limit: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Add timeout for operation (120 seconds), the desk moves twice
+		opCtx, cancel := context.WithTimeout(cmd.Context(), 120*time.Second)
+		defer cancel()
+
+		err = j.SetLimits(opCtx, lower, upper)
+		progress.finish()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set limits: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Limits set to %s - %s\n", formatLimit(j.UserLimits.Min, u), formatLimit(j.UserLimits.Max, u))
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var limitsClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clears the height limits of the desk",
+	Args:  cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		//Initialize device
+		var err error
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		opCtx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := j.ClearLimits(opCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to clear limits: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Limits cleared")
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// formatLimit formats a user limit in u, or "not set".
+func formatLimit(h jiecang.Height, u jiecang.Unit) string {
+	if h == 0 {
+		return "not set"
+	}
+	return h.Format(u)
+}
+
+func init() {
+	rootCmd.AddCommand(limitsCmd)
+	limitsCmd.AddCommand(limitsSetCmd)
+	limitsCmd.AddCommand(limitsClearCmd)
+
+	limitsSetCmd.Flags().Float64Var(&limitMin, "min", 0, "Lower height limit")
+	limitsSetCmd.Flags().Float64Var(&limitMax, "max", 0, "Upper height limit")
+}
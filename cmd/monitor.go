@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 Aris Tzermias
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tzermias/deskctl/pkg/jiecang"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// monitorCmd represents the monitor command
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Prints the notifications of the desk as they arrive",
+	Long: `Connects to the desk and prints every notification received from the
+	controller, decoded, along with the raw frame in hex. Movements started from
+	the handset are shown as well. Frames without a known meaning are printed as
+	unknown, which helps reverse-engineering new controllers. Received data that
+	is not part of a valid frame, e.g. a frame with a bad checksum, is printed
+	in hex as invalid.
+
+	The connection is re-established if it is lost. Press Ctrl+C to stop.`,
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		//Initialize device
+		var err error
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		events := j.SubscribeFrames(ctx)
+
+		supervised := make(chan error, 1)
+		go func() { supervised <- j.Supervise(ctx, dial) }()
+
+		fmt.Println("Monitoring desk, press Ctrl+C to stop")
+		for e := range events {
+			if line := describeEvent(e, displayUnit(j)); line != "" {
+				fmt.Printf("%s %s\n", time.Now().Format("15:04:05.000"), line)
+			}
+		}
+
+		if err := <-supervised; err != nil && !errors.Is(err, ctx.Err()) {
+			fmt.Fprintf(os.Stderr, "Connection failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// describeEvent returns the line printed for e, or an empty string for
+// events that repeat the information of a frame.
+func describeEvent(e jiecang.Event, u jiecang.Unit) string {
+	switch e := e.(type) {
+	case jiecang.FrameReceived:
+		if e.Err != nil {
+			return fmt.Sprintf("%-16s %-32s %x", "invalid", e.Err, e.Raw)
+		}
+		kind, detail := describeMessage(e.Message, u)
+		return fmt.Sprintf("%-16s %-32s %x", kind, detail, e.Raw)
+	case jiecang.MotionStarted:
+		return fmt.Sprintf("%-16s %s", "motion started", e.Height.Format(u))
+	case jiecang.MotionStopped:
+		return fmt.Sprintf("%-16s %s", "motion stopped", e.Height.Format(u))
+	case jiecang.Disconnected:
+		return fmt.Sprintf("%-16s %v", "disconnected", e.Err)
+	case jiecang.Connected:
+		return "connected"
+	}
+	return ""
+}
+
+// describeMessage returns the kind and the decoded content of m. Heights
+// are calibrated like the heights of the desk.
+func describeMessage(m protocol.Message, u jiecang.Unit) (string, string) {
+	height := func(h uint16) string {
+		calibrated, err := j.FromController(h)
+		if err != nil {
+			return fmt.Sprintf("%d mm (uncalibrated)", h)
+		}
+		return calibrated.Format(u)
+	}
+
+	switch m := m.(type) {
+	case protocol.HeightReport:
+		return "height", height(m.Height)
+	case protocol.HeightRange:
+		return "height range", fmt.Sprintf("%s - %s", height(m.Lowest), height(m.Highest))
+	case protocol.MemoryPreset:
+		if m.Height == 0 {
+			return fmt.Sprintf("memory %d", m.Slot), formatLimit(0, u)
+		}
+		return fmt.Sprintf("memory %d", m.Slot), height(m.Height)
+	case protocol.Units:
+		return "units", jiecang.Unit(m.Unit).String()
+	case protocol.MemoryMode:
+		mode := jiecang.MemoryModeOneTouch
+		if m.ConstantTouch {
+			mode = jiecang.MemoryModeConstantTouch
+		}
+		return "memory mode", mode.String()
+	case protocol.AntiCollision:
+		return "anti-collision", jiecang.AntiCollisionSensitivityName(m.Sensitivity)
+	case protocol.Limits:
+		return "limits", fmt.Sprintf("lower set: %t, upper set: %t", m.MinSet, m.MaxSet)
+	case protocol.MaxLimit:
+		return "upper limit", height(m.Height)
+	case protocol.MinLimit:
+		return "lower limit", height(m.Height)
+	case protocol.StandTime:
+		return "standing time", formatDuration(time.Duration(m.Minutes) * time.Minute)
+	case protocol.AllTime:
+		return "total time", formatDuration(time.Duration(m.Minutes) * time.Minute)
+	case protocol.Unknown:
+		return "unknown", fmt.Sprintf("type 0x%02x payload %x", m.Frame.Type, m.Frame.Payload)
+	}
+	return "message", fmt.Sprintf("%#v", m)
+}
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 Aris Tzermias
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// probeCmd represents the probe command
+var probeCmd = &cobra.Command{
+	Use:   "probe",
+	Short: "Finds out the capabilities of the desk controller",
+	Long: `Sends read-only queries to the controller, records which ones it answers
+	and saves the resulting capability profile for the desk. The profile is
+	then used by the other commands, which refuse the features the controller
+	does not support. None of the queries moves the desk or changes a setting.
+
+	Run it again after replacing the controller.`,
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		//Initialize device, ignoring the profile saved by a previous probe
+		var err error
+		skipProfile = true
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		opCtx, cancel := context.WithTimeout(cmd.Context(), 60*time.Second)
+		defer cancel()
+
+		p, err := j.Probe(opCtx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to probe controller: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, q := range p.Queries {
+			fmt.Printf("%-20s %s -> %s\n", q.Name, q.Command, strings.Join(q.Replies, " "))
+		}
+		fmt.Printf("Memory presets: %d\n", p.MemoryPresets)
+		fmt.Printf("Units: %s\n", supported(p.Units))
+		fmt.Printf("Memory mode: %s\n", supported(p.MemoryMode))
+		fmt.Printf("Anti-collision: %s\n", supported(p.AntiCollision))
+		fmt.Printf("Limits: %s\n", supported(p.Limits))
+		fmt.Printf("Usage statistics: %s\n", supported(p.Stats))
+		if len(p.Unknown17) > 0 {
+			fmt.Printf("Unknown 0x17 payloads: %s\n", strings.Join(p.Unknown17, ", "))
+		}
+
+		path, err := saveProfile(p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save profile: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Profile saved to %s\n", path)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// supported formats whether a feature is supported.
+func supported(ok bool) string {
+	if ok {
+		return "supported"
+	}
+	return "not supported"
+}
+
+func init() {
+	rootCmd.AddCommand(probeCmd)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2025 Aris Tzermias
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tzermias/deskctl/pkg/jiecang"
+)
+
+// profilePath returns the path of the capability profile of the desk
+// selected by the global flags, in the user configuration directory.
+func profilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	id := address
+	if serialPort != "" {
+		id = serialPort
+	}
+	// Keep a single path element, e.g. tcp___192.168.1.50_4000
+	id = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:`, r) {
+			return '_'
+		}
+		return r
+	}, strings.ToUpper(id))
+	return filepath.Join(dir, "deskctl", "profiles", id+".json"), nil
+}
+
+// loadProfile returns the capability profile of the desk selected by the
+// global flags, or nil if the desk was never probed.
+func loadProfile() (*jiecang.Profile, error) {
+	path, err := profilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p jiecang.Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// saveProfile saves p as the capability profile of the desk selected by the
+// global flags and returns its path.
+func saveProfile(p jiecang.Profile) (string, error) {
+	path, err := profilePath()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0o644)
+}
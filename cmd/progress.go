@@ -0,0 +1,36 @@
+/*
+Copyright © 2025 Aris Tzermias
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tzermias/deskctl/pkg/jiecang"
+)
+
+// progressBarWidth is the number of characters of the progress bar.
+const progressBarWidth = 30
+
+// progressBar renders the progress of a movement on a single terminal line.
+type progressBar struct {
+	out      io.Writer
+	rendered bool // Whether a line was rendered and not terminated yet
+}
+
+// update renders p, replacing the previously rendered line.
+func (b *progressBar) update(p jiecang.Progress) {
+	filled := int(p.Fraction() * progressBarWidth)
+	fmt.Fprintf(b.out, "\r[%s%s] %s", strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), p.Height.Format(displayUnit(j)))
+	b.rendered = true
+}
+
+// finish terminates the rendered line, if any.
+func (b *progressBar) finish() {
+	if b.rendered {
+		fmt.Fprintln(b.out)
+		b.rendered = false
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 Aris Tzermias
+*/
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+var (
+	rawBytes    []byte
+	rawWait     time.Duration
+	rawVerbatim bool
+)
+
+// rawCmd represents the raw command
+var rawCmd = &cobra.Command{
+	Use:   "raw [HEX]",
+	Short: "Sends a raw command to the controller",
+	Long: `Sends an arbitrary command to the controller and prints the replies
+	received within the time set by --wait, decoded when possible.
+
+	HEX is either the command type followed by its payload (e.g 0c or 1b0433),
+	in which case the preamble, length, checksum and terminator are added, or
+	a complete command frame (e.g f1f10c000c7e), whose checksum is verified.
+	With --verbatim, HEX is sent exactly as given, e.g. a frame with a wrong
+	checksum or another framing. Spaces and colons between bytes are ignored.
+
+	Use with care: commands are sent as is and may move the desk or change
+	its settings.`,
+	Args: cobra.ExactArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		var err error
+		if rawVerbatim {
+			rawBytes, err = parseHex(args[0])
+		} else {
+			var frame protocol.Frame
+			frame, err = parseRawFrame(args[0])
+			rawBytes = frame.Encode()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid command [%s]: %v\n", args[0], err)
+			os.Exit(1)
+		}
+
+		//Initialize device
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("Sent %x\n", rawBytes)
+		replies, err := j.SendBytes(cmd.Context(), rawBytes, rawWait)
+		for _, r := range replies {
+			fmt.Println(describeEvent(r, displayUnit(j)))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to send command: %v\n", err)
+			os.Exit(1)
+		}
+		if len(replies) == 0 {
+			fmt.Printf("No reply within %s\n", rawWait)
+		}
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// parseHex parses bytes given in hex, ignoring spaces and colons between
+// them.
+func parseHex(s string) ([]byte, error) {
+	s = strings.NewReplacer(" ", "", ":", "").Replace(s)
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return buf, nil
+}
+
+// parseRawFrame parses a command given in hex, either as type and payload
+// or as a complete frame.
+func parseRawFrame(s string) (protocol.Frame, error) {
+	buf, err := parseHex(s)
+	if err != nil {
+		return protocol.Frame{}, err
+	}
+
+	if len(buf) >= 2 && buf[0] == protocol.CommandPreamble && buf[1] == protocol.CommandPreamble {
+		return protocol.ParseCommand(buf)
+	}
+	if len(buf)-1 > 0xff {
+		return protocol.Frame{}, fmt.Errorf("payload too long (%d bytes)", len(buf)-1)
+	}
+	return protocol.Frame{Type: buf[0], Payload: buf[1:]}, nil
+}
+
+func init() {
+	rootCmd.AddCommand(rawCmd)
+	rawCmd.Flags().DurationVar(&rawWait, "wait", time.Second, "How long to wait for replies")
+	rawCmd.Flags().BoolVar(&rawVerbatim, "verbatim", false, "Send HEX exactly as given, without adding or verifying the framing and checksum")
+}
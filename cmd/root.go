@@ -6,35 +6,52 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/tzermias/deskctl/pkg/jiecang"
 	"tinygo.org/x/bluetooth"
 )
 
-var address string
+var (
+	address    string
+	serialPort string
+	baudRate   int
+	units      string
+	verbose    bool
+	traceFile  string
+)
+
+// traceOut is the file the protocol trace is written to, see --trace.
+var traceOut *os.File
+
+// skipProfile ignores the capability profile of the desk, see probe.
+var skipProfile bool
 
 var adapter *bluetooth.Adapter
 
+// dial dials the desk selected by the global flags, set by initDesk.
+var dial jiecang.Dialer
+
+// progress renders the movements of the desk on stdout.
+var progress = &progressBar{out: os.Stdout}
+
 var rootCmd = &cobra.Command{
 	Use:   "deskctl",
 	Short: "A CLI tool to control and manage Jiecang standing desks",
 	Long: `Controls standing desks equipped with Jiecang controllers
 Moves the desk up/down, manages memory presets`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Initialize bluetooth adapter
-		adapter = bluetooth.DefaultAdapter
-		err := adapter.Enable()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Could not enable Bluetooth adapter: %v\n", err)
-			os.Exit(1)
-		}
-	},
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = cmd.Help()
 		os.Exit(0)
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if traceOut != nil {
+			_ = traceOut.Close()
+		}
 	},
 }
 
@@ -46,5 +63,147 @@ func Execute(ctx context.Context) {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&address, "address", "a", "", "Device address")
+	rootCmd.PersistentFlags().StringVarP(&address, "address", "a", "", "Device address (Bluetooth MAC address or tcp://host:port of a serial bridge)")
+	rootCmd.PersistentFlags().StringVar(&serialPort, "serial", "", "Serial port connected to the controller (e.g /dev/ttyUSB0), used instead of Bluetooth")
+	rootCmd.PersistentFlags().IntVar(&baudRate, "baud", jiecang.DefaultBaudRate, "Baud rate of the serial port")
+	rootCmd.PersistentFlags().StringVar(&units, "units", "auto", "Units of heights (cm, in or auto to follow the controller setting)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Log communication with the controller to stderr")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace", "", "Record the communication with the controller to a JSON lines file")
+}
+
+// initDesk connects to the desk selected by the global flags and initializes
+// it. Heights are displayed in the units selected with --units.
+func initDesk() (*jiecang.Jiecang, error) {
+	var u jiecang.Unit
+	if units != "auto" {
+		var err error
+		if u, err = jiecang.ParseUnit(units); err != nil {
+			return nil, err
+		}
+	}
+
+	d, err := connectDesk()
+	if err != nil {
+		return nil, err
+	}
+	if units != "auto" {
+		d.SetDisplayUnits(u)
+	}
+	return d, nil
+}
+
+// deskOptions returns the options of the desk: progress is rendered on
+// stdout and warnings, or everything with --verbose, are logged to stderr.
+// With --trace, the communication is recorded to the trace file. The
+// capability profile of the desk is used if it was probed, and heights are
+// calibrated if the desk was calibrated.
+func deskOptions() ([]jiecang.Option, error) {
+	level := slog.LevelWarn
+	if verbose {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	opts := []jiecang.Option{jiecang.WithLogger(logger), jiecang.WithProgress(progress.update)}
+
+	if traceFile != "" {
+		f, err := os.Create(traceFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace file: %w", err)
+		}
+		traceOut = f
+		opts = append(opts, jiecang.WithTrace(f))
+	}
+
+	if !skipProfile {
+		p, err := loadProfile()
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			opts = append(opts, jiecang.WithProfile(*p))
+		}
+	}
+
+	offset, err := loadCalibration()
+	if err != nil {
+		return nil, err
+	}
+	return append(opts, jiecang.WithCalibration(offset)), nil
+}
+
+// displayUnit returns the units selected with --units, or the units setting
+// of the desk controller for auto.
+func displayUnit(d *jiecang.Jiecang) jiecang.Unit {
+	if u, err := jiecang.ParseUnit(units); err == nil {
+		return u
+	}
+	return d.Units
+}
+
+// connectDesk connects to the desk selected by the global flags and
+// initializes it. The dialer of the desk is kept in dial.
+func connectDesk() (*jiecang.Jiecang, error) {
+	var err error
+	if dial, err = deskDialer(); err != nil {
+		return nil, err
+	}
+
+	t, err := dial(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return newDesk(t)
+}
+
+// deskDialer returns the dialer of the desk selected by the global flags.
+// A serial port takes precedence over the address. Addresses in the form
+// tcp://host:port connect to a serial bridge, anything else is treated as a
+// Bluetooth MAC address.
+func deskDialer() (jiecang.Dialer, error) {
+	if serialPort != "" {
+		return func(ctx context.Context) (jiecang.Transport, error) {
+			return jiecang.NewSerialTransport(serialPort, baudRate)
+		}, nil
+	}
+
+	if strings.Contains(address, "://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address [%s]: %w", address, err)
+		}
+		if u.Scheme != "tcp" {
+			return nil, fmt.Errorf("unsupported address scheme %q", u.Scheme)
+		}
+		return func(ctx context.Context) (jiecang.Transport, error) {
+			return jiecang.NewTCPTransport(u.Host)
+		}, nil
+	}
+
+	// Validate MAC address
+	mac, err := bluetooth.ParseMAC(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAC address [%s]: %w", address, err)
+	}
+
+	// Initialize bluetooth adapter
+	adapter = bluetooth.DefaultAdapter
+	if err := adapter.Enable(); err != nil {
+		return nil, fmt.Errorf("could not enable Bluetooth adapter: %w", err)
+	}
+	return jiecang.BLEDialer(adapter, bluetooth.Address{MACAddress: bluetooth.MACAddress{MAC: mac}}), nil
+}
+
+// newDesk initializes a desk on top of t, closing t on failure.
+func newDesk(t jiecang.Transport) (*jiecang.Jiecang, error) {
+	opts, err := deskOptions()
+	if err != nil {
+		_ = t.Close()
+		return nil, err
+	}
+	d, err := jiecang.New(t, opts...)
+	if err != nil {
+		_ = t.Close()
+		return nil, err
+	}
+	return d, nil
 }
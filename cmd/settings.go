@@ -0,0 +1,193 @@
+/*
+Copyright © 2025 Aris Tzermias
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tzermias/deskctl/pkg/jiecang"
+)
+
+// settingsCmd represents the settings command
+var settingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Shows or changes the settings of the desk controller",
+	Long: `Shows the settings of the desk controller.
+
+	Use the subcommands to change a setting.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		//Initialize device
+		var err error
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("Units: %s\n", j.Units)
+		fmt.Printf("Memory mode: %s\n", j.MemoryMode())
+		fmt.Printf("Anti-collision sensitivity: %s\n", jiecang.AntiCollisionSensitivityName(j.AntiCollisionSensitivity))
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var unitsCmd = &cobra.Command{
+	Use:   "units [cm|in]",
+	Short: "Shows or sets the display units of the desk controller",
+	Long: `Shows the display units of the desk controller, or switches them to
+	centimeters (cm) or inches (in).`,
+	Args: cobra.MaximumNArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		var err error
+		if len(args) == 1 {
+			if _, err = jiecang.ParseUnit(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid units: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		//Initialize device
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println(j.Units)
+			return
+		}
+
+		u, _ := jiecang.ParseUnit(args[0])
+		opCtx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := j.SetUnits(opCtx, u); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set units: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Units set to %s\n", u)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var antiCollisionCmd = &cobra.Command{
+	Use:   "anti-collision [high|medium|low]",
+	Short: "Shows or sets the anti-collision sensitivity of the desk controller",
+	Long: `Shows the anti-collision sensitivity of the desk controller, or sets it
+	to high, medium or low.
+
+	Lower sensitivity helps desks that stop on their own because of shelving
+	or other loads.`,
+	Args: cobra.MaximumNArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		var err error
+		if len(args) == 1 {
+			if _, err = jiecang.ParseAntiCollisionSensitivity(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid sensitivity: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		//Initialize device
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println(jiecang.AntiCollisionSensitivityName(j.AntiCollisionSensitivity))
+			return
+		}
+
+		level, _ := jiecang.ParseAntiCollisionSensitivity(args[0])
+		opCtx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := j.SetAntiCollisionSensitivity(opCtx, level); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set anti-collision sensitivity: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Anti-collision sensitivity set to %s\n", jiecang.AntiCollisionSensitivityName(level))
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var memoryModeCmd = &cobra.Command{
+	Use:   "memory-mode [one-touch|constant]",
+	Short: "Shows or sets the memory mode of the desk controller",
+	Long: `Shows the memory mode of the desk controller, or sets it.
+
+	In one-touch mode a single press of a memory button moves the desk to the
+	preset. In constant mode the button has to be held until the desk arrives.`,
+	Args: cobra.MaximumNArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		var err error
+		if len(args) == 1 {
+			if _, err = jiecang.ParseMemoryMode(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid memory mode: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		//Initialize device
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println(j.MemoryMode())
+			return
+		}
+
+		mode, _ := jiecang.ParseMemoryMode(args[0])
+		opCtx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := j.SetMemoryMode(opCtx, mode); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set memory mode: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Memory mode set to %s\n", mode)
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(settingsCmd)
+	settingsCmd.AddCommand(unitsCmd)
+	settingsCmd.AddCommand(antiCollisionCmd)
+	settingsCmd.AddCommand(memoryModeCmd)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 Aris Tzermias
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Shows the usage statistics of the desk",
+	Long: `Shows the standing time and total usage time counted by the desk
+	controller.`,
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		//Initialize device
+		var err error
+		j, err = initDesk()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		opCtx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		stats, err := j.QueryUsageStats(opCtx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fetch usage statistics: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Standing time: %s\n", formatDuration(stats.StandingTime))
+		fmt.Printf("Total time: %s\n", formatDuration(stats.TotalTime))
+		if stats.TotalTime > 0 {
+			fmt.Printf("Standing: %.1f%%\n", 100*stats.StandingTime.Hours()/stats.TotalTime.Hours())
+		}
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := j.Disconnect(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error when disconnecting: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// formatDuration formats d in hours and minutes (e.g. "12h 05m").
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
@@ -4,12 +4,18 @@ Copyright © 2025 Aris Tzermias
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tzermias/deskctl/pkg/jiecang"
-	"tinygo.org/x/bluetooth"
+)
+
+var (
+	holdFor  time.Duration
+	moveDist float64
 )
 
 var upCmd = &cobra.Command{
@@ -18,28 +24,27 @@ var upCmd = &cobra.Command{
 	Long: `Moves the desk up by one unit. 
 
 	This command is equivalent of pressing the up button in your standing desk control once.
+	With --for or --by, the button is held for the given time or until the desk moved
+	by the given distance, in the units selected with --units.
 	`,
 	PreRun: func(cmd *cobra.Command, args []string) {
-		// Validate MAC address
-		var mac bluetooth.MAC
-		mac, err := bluetooth.ParseMAC(address)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid MAC address [%s]: %v\n", address, err)
-			os.Exit(1)
-		}
-
 		//Initialize device
-		j, err = jiecang.Init(adapter, bluetooth.Address{MACAddress: bluetooth.MACAddress{MAC: mac}})
+		var err error
+		j, err = initDesk()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
 			os.Exit(1)
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := j.Up(); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to move desk up: %v\n", err)
-			os.Exit(1)
+		if holdFor == 0 && moveDist == 0 {
+			if err := j.Up(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to move desk up: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		}
+		holdButton(cmd.Context(), jiecang.DirectionUp)
 	},
 	PostRun: func(cmd *cobra.Command, args []string) {
 		if err := j.Disconnect(); err != nil {
@@ -55,28 +60,27 @@ var downCmd = &cobra.Command{
 	Long: `Moves the desk down by one unit. 
 
 	This command is equivalent of pressing the down button in your standing desk control once.
+	With --for or --by, the button is held for the given time or until the desk moved
+	by the given distance, in the units selected with --units.
 	`,
 	PreRun: func(cmd *cobra.Command, args []string) {
-		// Validate MAC address
-		var mac bluetooth.MAC
-		mac, err := bluetooth.ParseMAC(address)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid MAC address [%s]: %v\n", address, err)
-			os.Exit(1)
-		}
-
 		//Initialize device
-		j, err = jiecang.Init(adapter, bluetooth.Address{MACAddress: bluetooth.MACAddress{MAC: mac}})
+		var err error
+		j, err = initDesk()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to initialize device: %v\n", err)
 			os.Exit(1)
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := j.Down(); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to move desk down: %v\n", err)
-			os.Exit(1)
+		if holdFor == 0 && moveDist == 0 {
+			if err := j.Down(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to move desk down: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		}
+		holdButton(cmd.Context(), jiecang.DirectionDown)
 	},
 	PostRun: func(cmd *cobra.Command, args []string) {
 		if err := j.Disconnect(); err != nil {
@@ -86,7 +90,48 @@ var downCmd = &cobra.Command{
 	},
 }
 
+// holdButton moves the desk in direction dir for --for, or by --by.
+func holdButton(ctx context.Context, dir jiecang.Direction) {
+	if holdFor < 0 || moveDist < 0 {
+		fmt.Fprintln(os.Stderr, "--for and --by must be positive")
+		os.Exit(1)
+	}
+
+	// Add timeout for operation (60 seconds, on top of --for)
+	opCtx, cancel := context.WithTimeout(ctx, holdFor+60*time.Second)
+	defer cancel()
+
+	var res jiecang.MoveResult
+	var err error
+	if holdFor > 0 {
+		res, err = j.MoveFor(opCtx, dir, holdFor)
+	} else {
+		var distance jiecang.Height
+		if distance, err = jiecang.ParseHeight(moveDist, displayUnit(j)); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid distance: %v\n", err)
+			os.Exit(1)
+		}
+		delta := int(distance)
+		if dir == jiecang.DirectionDown {
+			delta = -delta
+		}
+		res, err = j.MoveBy(opCtx, delta)
+	}
+	progress.finish()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to move desk %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+	checkMove(res)
+}
+
 func init() {
 	rootCmd.AddCommand(upCmd)
 	rootCmd.AddCommand(downCmd)
+
+	for _, c := range []*cobra.Command{upCmd, downCmd} {
+		c.Flags().DurationVar(&holdFor, "for", 0, "Hold the button for this long (e.g 2s)")
+		c.Flags().Float64Var(&moveDist, "by", 0, "Hold the button until the desk moved by this distance")
+		c.MarkFlagsMutuallyExclusive("for", "by")
+	}
 }
@@ -0,0 +1,112 @@
+package jiecang
+
+import (
+	"context"
+	"fmt"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// BLETransport is a Transport that talks to the controller through the
+// Lierda BLE module, using the 0xFE61 characteristic for commands and
+// notifications on the 0xFE62 characteristic for responses.
+type BLETransport struct {
+	device  bluetooth.Device               // BLE device connection
+	dataIn  bluetooth.DeviceCharacteristic // Write characteristic for sending commands
+	dataOut bluetooth.DeviceCharacteristic // Read characteristic for receiving responses
+}
+
+// NewBLETransport connects to the BLE device at the specified address and
+// discovers the Jiecang service (0xFE60) along with its data input/output
+// characteristics (0xFE61, 0xFE62).
+//
+// Returns an error if the connection, service discovery or characteristic
+// discovery fails.
+func NewBLETransport(a *bluetooth.Adapter, addr bluetooth.Address) (*BLETransport, error) {
+	t := new(BLETransport)
+
+	// Connect to BLE Device
+	d, err := a.Connect(addr, bluetooth.ConnectionParams{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to device: %w", err)
+	}
+
+	// Scan Services and characteristics
+	services, err := d.DiscoverServices([]bluetooth.UUID{
+		bluetooth.New16BitUUID(BLEDeviceId),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover services: %w", err)
+	}
+
+	serviceFound := false
+	for _, service := range services {
+		if service.UUID() != bluetooth.New16BitUUID(BLEDeviceId) {
+			// Wrong service
+			continue
+		}
+		serviceFound = true
+
+		// Found the correct service
+		// Get a list of characteristics below the service
+		characteristics, err := service.DiscoverCharacteristics([]bluetooth.UUID{
+			bluetooth.New16BitUUID(BLECharDataInId),
+			bluetooth.New16BitUUID(BLECharDataOutId),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover characteristics: %w", err)
+		}
+
+		if len(characteristics) < 2 {
+			return nil, fmt.Errorf("expected 2 characteristics, got %d", len(characteristics))
+		}
+
+		t.dataIn = characteristics[0]
+		t.dataOut = characteristics[1]
+	}
+
+	if !serviceFound {
+		return nil, fmt.Errorf("BLE service %04x not found", BLEDeviceId)
+	}
+
+	t.device = d
+	return t, nil
+}
+
+// Write sends frame to the DataIn characteristic.
+// simple wrapper to bluetooth.WriteWithoutResponse
+func (t *BLETransport) Write(frame []byte) error {
+	_, err := t.dataIn.WriteWithoutResponse(frame)
+	return err
+}
+
+// Subscribe enables notifications on the DataOut characteristic and
+// forwards every notification to handler.
+func (t *BLETransport) Subscribe(handler func(buf []byte)) error {
+	// Enable notifications on dataOut
+	if err := t.dataOut.EnableNotifications(handler); err != nil {
+		return fmt.Errorf("failed to enable notifications: %w", err)
+	}
+
+	// Read desk current height
+	result := []byte{}
+	if _, err := t.dataOut.Read(result); err != nil {
+		return fmt.Errorf("failed to read initial height: %w", err)
+	}
+	return nil
+}
+
+// Close disconnects from the BLE device.
+func (t *BLETransport) Close() error {
+	return t.device.Disconnect()
+}
+
+// BLEDialer returns a Dialer connecting to the BLE device at addr, for use
+// with Supervise. Every call connects again and rediscovers the Jiecang
+// service and characteristics.
+func BLEDialer(a *bluetooth.Adapter, addr bluetooth.Address) Dialer {
+	return func(ctx context.Context) (Transport, error) {
+		return NewBLETransport(a, addr)
+	}
+}
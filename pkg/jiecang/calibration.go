@@ -0,0 +1,87 @@
+package jiecang
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// This file contains the height calibration. The height reported by the
+// controller often differs from the height of the desk surface measured
+// with a tape, e.g. because of the tabletop thickness. A calibration offset
+// is added to every height received from the controller and subtracted from
+// every height sent to it, so that the package only deals with real heights.
+
+// WithCalibration sets the calibration offset of the desk, in millimeters:
+// the difference between the real height of the desk surface and the height
+// reported by the controller. See CalibrationOffset to find it.
+func WithCalibration(offset int) Option {
+	return func(j *Jiecang) {
+		j.calibration = offset
+	}
+}
+
+// Calibration returns the calibration offset of the desk in millimeters,
+// see WithCalibration.
+func (j *Jiecang) Calibration() int {
+	return j.calibration
+}
+
+// FromController returns the real height of h, a height reported by the
+// controller, e.g. in the Message of a FrameReceived event.
+//
+// Returns an error if the calibration offset takes h out of the range of
+// Height.
+func (j *Jiecang) FromController(h uint16) (Height, error) {
+	height := int(h) + j.calibration
+	if height < 0 || height > math.MaxUint16 {
+		return 0, fmt.Errorf("reported height %d mm with calibration offset %d mm is out of range", h, j.calibration)
+	}
+	return Height(height), nil
+}
+
+// savedFromController is FromController for the heights of memory presets
+// and limits, for which the controller reports 0 when they are not set. A
+// zero height stays 0.
+func (j *Jiecang) savedFromController(h uint16) (Height, error) {
+	if h == 0 {
+		return 0, nil
+	}
+	return j.FromController(h)
+}
+
+// toController returns h as sent to the controller, the inverse of
+// FromController.
+//
+// Returns an error if the calibration offset takes h out of the heights the
+// controller can represent.
+func (j *Jiecang) toController(h Height) (uint16, error) {
+	reported := int(h) - j.calibration
+	if reported < 0 || reported > math.MaxUint16 {
+		return 0, fmt.Errorf("height %s with calibration offset %d mm is out of range", j.format(h), j.calibration)
+	}
+	return uint16(reported), nil
+}
+
+// CalibrationOffset returns the calibration offset making the current
+// height of the desk read as actual, the height of the desk surface
+// measured with a tape. The offset only applies to later connections, see
+// WithCalibration.
+//
+// Returns an error if the command transmission fails, the controller does
+// not answer (ErrTimeout) or ctx is cancelled.
+//
+// Example:
+//
+//	offset, err := desk.CalibrationOffset(ctx, jiecang.Centimeters(73.5))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	desk, err = jiecang.New(t, jiecang.WithCalibration(offset))
+func (j *Jiecang) CalibrationOffset(ctx context.Context, actual Height) (int, error) {
+	h, err := j.QueryHeight(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int(actual) - (int(h) - j.calibration), nil
+}
@@ -0,0 +1,132 @@
+package jiecang
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestWithCalibration(t *testing.T) {
+	ft := newFakeTransport()
+	ft.responses[protocol.CmdFetchSettings] = append(ft.responses[protocol.CmdFetchSettings], heightReply(800))
+	ft.responses[protocol.CmdQueryLimits] = [][]byte{reply(0x20, 0x01), reply(0x21, 0x04, 0xb0)}
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == protocol.CmdGoToHeight {
+			f.notify(heightReply(985))
+		}
+	}
+
+	j, err := New(ft, WithCalibration(15))
+	require.NoError(t, err)
+	assert.Equal(t, 15, j.Calibration())
+
+	// Heights received from the controller are calibrated
+	assert.Equal(t, Range{Lowest: 635, Highest: 1287}, Range{Lowest: j.LowestHeight, Highest: j.HighestHeight})
+	assert.Equal(t, Limits{Max: 1215}, j.UserLimits)
+	assert.Equal(t, Height(1117), j.presets["memory1"])
+	assert.Equal(t, Height(0), j.presets["memory3"], "Presets that are not set stay 0")
+
+	h, err := j.QueryHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Height(815), h)
+
+	// Heights sent to the controller are not
+	res, err := j.GoToHeight(context.Background(), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, Arrived, res.Outcome)
+	assert.Equal(t, Height(1000), res.Height)
+	assert.Equal(t, 1, ft.count(protocol.GoToHeight(985).Encode()))
+}
+
+func TestCalibrationOffset(t *testing.T) {
+	tests := []struct {
+		name        string // Name of the testcase
+		calibration int    // Calibration offset of the desk
+		actual      Height // Input
+		expected    int    // Expected result
+	}{
+		{
+			name:     "Uncalibrated desk",
+			actual:   815,
+			expected: 15,
+		},
+		{
+			name:        "Calibrated desk",
+			calibration: 15,
+			actual:      790,
+			expected:    -10,
+		},
+	}
+
+	for _, test := range tests {
+		ft := newFakeTransport()
+		ft.responses[protocol.CmdFetchSettings] = append(ft.responses[protocol.CmdFetchSettings], heightReply(800))
+		j, err := New(ft, WithCalibration(test.calibration))
+		require.NoError(t, err, test.name)
+
+		offset, err := j.CalibrationOffset(context.Background(), test.actual)
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, offset, test.name)
+	}
+}
+
+func TestFromController(t *testing.T) {
+	tests := []struct {
+		name        string // Name of the testcase
+		calibration int    // Calibration offset of the desk
+		input       uint16 // Input
+		expected    Height // Expected result
+		expectedErr bool   // Whether an error is expected
+	}{
+		{
+			name:        "Positive offset",
+			calibration: 15,
+			input:       800,
+			expected:    815,
+		},
+		{
+			name:        "Zero height is a height",
+			calibration: 15,
+			input:       0,
+			expected:    15,
+		},
+		{
+			name:        "Negative result",
+			calibration: -20,
+			input:       10,
+			expectedErr: true,
+		},
+		{
+			name:        "Beyond the range of Height",
+			calibration: 20,
+			input:       65530,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		j := &Jiecang{calibration: test.calibration}
+		h, err := j.FromController(test.input)
+		if test.expectedErr {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, h, test.name)
+
+		reported, err := j.toController(h)
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.input, reported, test.name)
+	}
+
+	j := &Jiecang{calibration: 15}
+	h, err := j.savedFromController(0)
+	require.NoError(t, err)
+	assert.Equal(t, Height(0), h, "Presets and limits that are not set stay 0")
+
+	_, err = j.toController(10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,259 @@
+package jiecang
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// This file contains the event subscription API, which pushes desk state
+// changes to consumers as they are received from the controller.
+
+const (
+	// eventBufferSize is the number of events buffered per subscriber.
+	// Events are dropped for subscribers whose buffer is full.
+	eventBufferSize = 64
+
+	// defaultMotionIdle is how long the height has to stay the same before
+	// the desk is considered stopped.
+	defaultMotionIdle = 500 * time.Millisecond
+)
+
+// Event is a change of the desk state. It is one of HeightChanged,
+// MotionStarted, MotionStopped, PresetUpdated, SettingsChanged,
+// UnknownFrame, FrameReceived, Connected or Disconnected.
+type Event interface {
+	isEvent()
+}
+
+// HeightChanged is sent when the controller reports a new height.
+type HeightChanged struct {
+	Height Height
+}
+
+// MotionStarted is sent when the desk starts moving, including movements
+// started from the desk control panel.
+type MotionStarted struct {
+	Height Height // Height when the movement started
+}
+
+// MotionStopped is sent when the desk has not moved for a short while
+// after a movement.
+type MotionStopped struct {
+	Height Height // Height when the movement stopped
+}
+
+// PresetUpdated is sent when the height of a memory preset changes.
+type PresetUpdated struct {
+	Slot   int // Memory preset number (1-4)
+	Height Height
+}
+
+// SettingsChanged is sent when a setting of the controller changes. It
+// carries the current value of all settings.
+type SettingsChanged struct {
+	MemoryConstantTouchMode  bool
+	AntiCollisionSensitivity uint8
+	Units                    Unit
+}
+
+// UnknownFrame is sent for every notification without a known meaning.
+type UnknownFrame struct {
+	Frame protocol.Frame
+}
+
+// FrameReceived is sent for every frame received from the controller,
+// before the events resulting from it, to subscribers of SubscribeFrames.
+// Message is the decoded frame, or nil if it could not be decoded, in which
+// case Err tells why. Received data that is not part of a valid frame, such
+// as a frame with a bad checksum, is sent as well, with Raw holding the
+// skipped bytes.
+type FrameReceived struct {
+	Raw     []byte           // Frame as received, including header and checksum
+	Message protocol.Message // Decoded frame
+	Err     error            // Decoding error
+}
+
+// Connected is sent by Supervise when the connection to the controller is
+// re-established and the desk state is refreshed.
+type Connected struct{}
+
+// Disconnected is sent by Supervise when the connection to the controller
+// is found to be lost.
+type Disconnected struct {
+	Err error // Why the connection is considered lost
+}
+
+func (HeightChanged) isEvent()   {}
+func (MotionStarted) isEvent()   {}
+func (MotionStopped) isEvent()   {}
+func (PresetUpdated) isEvent()   {}
+func (SettingsChanged) isEvent() {}
+func (UnknownFrame) isEvent()    {}
+func (FrameReceived) isEvent()   {}
+func (Connected) isEvent()       {}
+func (Disconnected) isEvent()    {}
+
+// subscriber is a single consumer of events.
+type subscriber struct {
+	ch      chan Event
+	frames  bool // Whether FrameReceived events are sent
+	dropped uint64
+}
+
+// eventBus fans out events to every subscriber.
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	dropped     uint64
+	closed      bool
+	done        chan struct{} // Closed by close
+}
+
+// subscribe adds a new subscriber, removed when ctx is done or the bus is
+// closed. FrameReceived events are only sent to the subscriber if frames is
+// true.
+func (b *eventBus) subscribe(ctx context.Context, frames bool) <-chan Event {
+	s := &subscriber{ch: make(chan Event, eventBufferSize), frames: frames}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.ch)
+		return s.ch
+	}
+	if b.subscribers == nil {
+		b.subscribers = make(map[*subscriber]struct{})
+	}
+	if b.done == nil {
+		b.done = make(chan struct{})
+	}
+	b.subscribers[s] = struct{}{}
+
+	done := b.done
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[s]; ok {
+			delete(b.subscribers, s)
+			close(s.ch)
+		}
+	}()
+	return s.ch
+}
+
+// publish sends e to every subscriber, without blocking.
+func (b *eventBus) publish(e Event) {
+	_, isFrame := e.(FrameReceived)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subscribers {
+		if isFrame && !s.frames {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped++
+			b.dropped++
+		}
+	}
+}
+
+// close closes the channel of every subscriber. No further subscribers are
+// accepted.
+func (b *eventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subscribers {
+		delete(b.subscribers, s)
+		close(s.ch)
+	}
+	if !b.closed && b.done != nil {
+		close(b.done)
+	}
+	b.closed = true
+}
+
+// Subscribe returns a channel receiving the events of the desk, until ctx
+// is done or the desk is disconnected, at which point the channel is
+// closed.
+//
+// Every subscriber has its own buffer. Events are dropped, rather than
+// delaying other subscribers, if the buffer is full; see DroppedEvents.
+//
+// Example:
+//
+//	for e := range desk.Subscribe(ctx) {
+//	    if h, ok := e.(jiecang.HeightChanged); ok {
+//	        fmt.Printf("Height: %s\n", h.Height)
+//	    }
+//	}
+func (j *Jiecang) Subscribe(ctx context.Context) <-chan Event {
+	return j.events.subscribe(ctx, false)
+}
+
+// SubscribeFrames is like Subscribe, but the channel also receives a
+// FrameReceived event for every frame received from the controller,
+// including frames that could not be decoded and data that is not part of
+// a valid frame. Useful to monitor the
+// communication with the controller.
+func (j *Jiecang) SubscribeFrames(ctx context.Context) <-chan Event {
+	return j.events.subscribe(ctx, true)
+}
+
+// DroppedEvents returns the number of events dropped so far because a
+// subscriber was not keeping up, across all subscribers.
+func (j *Jiecang) DroppedEvents() uint64 {
+	j.events.mu.Lock()
+	defer j.events.mu.Unlock()
+	return j.events.dropped
+}
+
+// trackMotion updates the motion state after a height report and returns
+// the events to publish. The desk is considered stopped once its height
+// has not changed for the motion idle time. Must be called with j.mu held.
+func (j *Jiecang) trackMotion(previous, height Height) []Event {
+	if !j.heightKnown {
+		// First report, there is no previous height to compare with
+		j.heightKnown = true
+		return []Event{HeightChanged{Height: height}}
+	}
+	if previous == height {
+		return nil
+	}
+
+	events := []Event{HeightChanged{Height: height}}
+	if !j.moving {
+		j.moving = true
+		events = append([]Event{MotionStarted{Height: previous}}, events...)
+	}
+
+	if j.motionTimer != nil {
+		j.motionTimer.Stop()
+	}
+	j.motionTimer = time.AfterFunc(j.motionIdle, j.motionStopped)
+	return events
+}
+
+// motionStopped is called when the height has not changed for the motion
+// idle time.
+func (j *Jiecang) motionStopped() {
+	j.mu.Lock()
+	if !j.moving {
+		j.mu.Unlock()
+		return
+	}
+	j.moving = false
+	height := j.currentHeight
+	j.mu.Unlock()
+
+	j.events.publish(MotionStopped{Height: height})
+}
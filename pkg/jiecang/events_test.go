@@ -0,0 +1,167 @@
+package jiecang
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// nextEvent waits for the next event on ch.
+func nextEvent(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+		return nil
+	}
+}
+
+func TestSubscribeMotion(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	j.motionIdle = 20 * time.Millisecond
+	ft.notify(heightReply(800))
+
+	events := j.Subscribe(context.Background())
+	ft.notify(heightReply(800))
+	ft.notify(heightReply(803))
+	ft.notify(heightReply(806))
+
+	assert.Equal(t, MotionStarted{Height: 800}, nextEvent(t, events))
+	assert.Equal(t, HeightChanged{Height: 803}, nextEvent(t, events))
+	assert.Equal(t, HeightChanged{Height: 806}, nextEvent(t, events))
+	assert.Equal(t, MotionStopped{Height: 806}, nextEvent(t, events))
+}
+
+func TestSubscribeFanOut(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	first := j.Subscribe(context.Background())
+	second := j.Subscribe(context.Background())
+
+	ft.notify(reply(0x27, 0x03, 0x20))
+	ft.notify(reply(0x1d, 0x02))
+	ft.notify(reply(0x17, 0x01))
+
+	for _, events := range []<-chan Event{first, second} {
+		assert.Equal(t, PresetUpdated{Slot: 3, Height: 800}, nextEvent(t, events))
+		assert.Equal(t, SettingsChanged{AntiCollisionSensitivity: 2}, nextEvent(t, events))
+		assert.Equal(t, UnknownFrame{Frame: protocol.Frame{Type: 0x17, Payload: []byte{0x01}}}, nextEvent(t, events))
+	}
+}
+
+func TestSubscribeUnchanged(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	events := j.Subscribe(context.Background())
+	// Presets are answered again with the same values
+	_, err = j.QueryPresets(context.Background())
+	require.NoError(t, err)
+
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event %#v", e)
+	default:
+	}
+}
+
+func TestSubscribeDropped(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Subscriber never reads its events
+	_ = j.Subscribe(context.Background())
+	for i := 0; i < eventBufferSize+10; i++ {
+		ft.notify(reply(0x17, byte(i)))
+	}
+	assert.Equal(t, uint64(10), j.DroppedEvents())
+}
+
+func TestSubscribeClosed(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := j.Subscribe(ctx)
+	disconnected := j.Subscribe(context.Background())
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-cancelled
+		return !ok
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, j.Disconnect())
+	_, ok := <-disconnected
+	assert.False(t, ok)
+
+	// Subscribing after disconnecting returns a closed channel
+	_, ok = <-j.Subscribe(context.Background())
+	assert.False(t, ok)
+}
+
+func TestSubscribeClosedReleased(t *testing.T) {
+	var b eventBus
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		_ = b.subscribe(context.Background(), false)
+	}
+
+	// Subscribers whose context is never done are released by close
+	b.close()
+	assert.Empty(t, b.subscribers)
+	// Not assert.Eventually, which runs the condition in a goroutine
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestSubscribeFrames(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	events := j.Subscribe(context.Background())
+	frames := j.SubscribeFrames(context.Background())
+
+	ft.notify(reply(0x1d, 0x02))
+	ft.notify(reply(0x1d, 0x01, 0x02))
+	ft.notify(reply(0x17, 0x01))
+	// Bad checksum
+	ft.notify([]byte{0xf2, 0xf2, 0x1d, 0x01, 0x02, 0x00, 0x7e})
+
+	assert.Equal(t, FrameReceived{Raw: reply(0x1d, 0x02), Message: protocol.AntiCollision{Sensitivity: 2}}, nextEvent(t, frames))
+	assert.Equal(t, SettingsChanged{AntiCollisionSensitivity: 2}, nextEvent(t, frames))
+	invalid, ok := nextEvent(t, frames).(FrameReceived)
+	require.True(t, ok)
+	assert.Equal(t, reply(0x1d, 0x01, 0x02), invalid.Raw)
+	assert.Nil(t, invalid.Message)
+	assert.Error(t, invalid.Err)
+	assert.Equal(t, FrameReceived{Raw: reply(0x17, 0x01), Message: protocol.Unknown{Frame: protocol.Frame{Type: 0x17, Payload: []byte{0x01}}}}, nextEvent(t, frames))
+	assert.Equal(t, UnknownFrame{Frame: protocol.Frame{Type: 0x17, Payload: []byte{0x01}}}, nextEvent(t, frames))
+	dropped, ok := nextEvent(t, frames).(FrameReceived)
+	require.True(t, ok)
+	assert.Equal(t, []byte{0xf2, 0xf2, 0x1d, 0x01, 0x02, 0x00, 0x7e}, dropped.Raw)
+	assert.Nil(t, dropped.Message)
+	assert.ErrorIs(t, dropped.Err, protocol.ErrInvalidFrame)
+
+	// Plain subscribers do not receive frames
+	assert.Equal(t, SettingsChanged{AntiCollisionSensitivity: 2}, nextEvent(t, events))
+	assert.Equal(t, UnknownFrame{Frame: protocol.Frame{Type: 0x17, Payload: []byte{0x01}}}, nextEvent(t, events))
+}
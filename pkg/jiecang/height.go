@@ -3,8 +3,8 @@ package jiecang
 import (
 	"context"
 	"fmt"
-	"math"
-	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
 )
 
 // This file contains functions for controlling desk height.
@@ -23,72 +23,44 @@ func (j *Jiecang) Down() error {
 	return j.sendCommand(commands["down"])
 }
 
-// GoToHeight moves the desk to the specified height in centimeters.
+// GoToHeight moves the desk to the specified height, with millimeter precision.
 //
-// The function validates that the target height is within the desk's configured
-// limits (LowestHeight and HighestHeight), then sends movement commands and polls
-// the current height until the target is reached or the context is cancelled.
+// The function validates that the target height is within the desk's allowed
+// range (see AllowedRange), then repeats the movement command and follows the
+// height reports until the desk arrives within ArrivalTolerance of the
+// target, stalls, reverses or the context is cancelled.
 //
 // Parameters:
 //   - ctx: Context for timeout and cancellation. The operation can be interrupted
 //     by cancelling the context (e.g., with Ctrl+C or timeout).
-//   - height: Target height in centimeters. Must be between LowestHeight and
-//     HighestHeight (typically 60-120cm).
+//   - height: Target height. Must be between LowestHeight and HighestHeight
+//     (typically 60-120cm), and within the user limits if set.
+//
+// Returns a MoveResult with the outcome of the movement (Arrived, Stalled,
+// CollisionSuspected or Cancelled) and the final height. The desk is stopped
+// unless it arrived.
 //
 // Returns an error if:
 //   - The target height is out of range
 //   - Command transmission fails
-//   - The context is cancelled (returns ctx.Err())
 //
-// The function polls the height every 200ms and sends a stop command when
-// the target is reached or the operation is cancelled.
-func (j *Jiecang) GoToHeight(ctx context.Context, height uint8) error {
+// Example:
+//
+//	res, err := desk.GoToHeight(ctx, jiecang.Centimeters(107.5))
+//	if err == nil && res.Outcome != jiecang.Arrived {
+//	    fmt.Printf("Desk %s at %s\n", res.Outcome, res.Height)
+//	}
+func (j *Jiecang) GoToHeight(ctx context.Context, height Height) (MoveResult, error) {
 	//Ensure that height is within low and high limits of the desk.
-	if height > j.HighestHeight || height < j.LowestHeight {
-		return fmt.Errorf("height %d is out of range (low: %d, high: %d)", height, j.LowestHeight, j.HighestHeight)
-	}
-	data0 := byte((int(height) * 10) / 256)
-	data1 := byte((int(height) * 10) % 256)
-	command := []byte{
-		0xf1,
-		0xf1,
-		0x1b,
-		0x02,
-		data0,
-		data1,
-		byte((int(0x1b) + int(0x02) + int(data0) + int(data1)) % 256),
-		0x7e,
+	r := j.AllowedRange()
+	if height > r.Highest || height < r.Lowest {
+		return MoveResult{}, fmt.Errorf("height %s is out of range (low: %s, high: %s)", j.format(height), j.format(r.Lowest), j.format(r.Highest))
 	}
-
-	ticker := time.NewTicker(200 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		j.mu.RLock()
-		currentHeight := j.currentHeight
-		j.mu.RUnlock()
-
-		if currentHeight == height {
-			fmt.Printf("\rHeight: %d cm\n", currentHeight)
-			break
-		}
-
-		select {
-		case <-ctx.Done():
-			// Context cancelled, send stop command and return
-			if err := j.sendCommand(commands["stop"]); err != nil {
-				return fmt.Errorf("failed to send stop command: %w", err)
-			}
-			fmt.Printf("\nOperation cancelled at height %d cm\n", currentHeight)
-			return nil
-		case <-ticker.C:
-			fmt.Printf("\rHeight: %d cm", currentHeight)
-			if err := j.sendCommand(command); err != nil {
-				return fmt.Errorf("failed to send goto command: %w", err)
-			}
-		}
+	reported, err := j.toController(height)
+	if err != nil {
+		return MoveResult{}, err
 	}
-	return nil
+	return j.move(ctx, protocol.GoToHeight(reported), height, moveOptions{})
 }
 
 // FetchHeight requests the desk's saved memory preset heights from the controller.
@@ -96,7 +68,7 @@ func (j *Jiecang) GoToHeight(ctx context.Context, height uint8) error {
 //
 // The response contains the height values for all memory presets (1-4).
 // The values are processed asynchronously by the characteristicReceiver callback
-// and stored in the presets map.
+// and stored in the presets map. Use QueryPresets to wait for the values.
 //
 // Returns an error if the command transmission fails.
 func (j *Jiecang) FetchHeight() error {
@@ -106,58 +78,31 @@ func (j *Jiecang) FetchHeight() error {
 	return j.sendCommand(commands["fetch_height"])
 }
 
-// FetchHeightRange requests the desk's minimum and maximum height limits.
-// The command is sent twice as required by the protocol for reliability.
+// FetchHeightRange requests the desk's physical height range and its
+// user-defined height limits. The commands are sent twice as required by the
+// protocol for reliability.
 //
-// The response contains the highest and lowest height values that the desk
-// can physically reach. The values are processed asynchronously by the
-// characteristicReceiver callback and stored in HighestHeight and LowestHeight.
+// The responses contain the highest and lowest height values that the desk
+// can physically reach, and the user limits restricting them. The values are
+// processed asynchronously by the characteristicReceiver callback and stored
+// in HighestHeight and LowestHeight, and UserLimits respectively.
+// Use QueryHeightRange and QueryLimits to wait for the values. The limits
+// are not requested if the profile of the controller does not support them,
+// see WithProfile.
 //
 // Returns an error if the command transmission fails.
 func (j *Jiecang) FetchHeightRange() error {
-	if err := j.sendCommand(commands["fetch_height_range"]); err != nil {
-		return err
+	queries := []string{"fetch_height_range"}
+	if j.supportsLimits() == nil {
+		queries = append(queries, "query_limits")
 	}
-	return j.sendCommand(commands["fetch_height_range"])
-}
-
-// readHeight decodes the current height value from the controller response.
-// The function extracts the height from bytes 4-5 of the response buffer and converts
-// it from millimeters (protocol format) to centimeters (application format).
-//
-// Parameters:
-//   - buf: Response buffer from the controller. Expected format:
-//     [0xf2, 0xf2, type, 0x03, highByte, lowByte, ..., checksum, 0x7e]
-//
-// Returns the current height in centimeters, or 0 if the response type is invalid.
-func readHeight(buf []byte) uint8 {
-	if buf[3] == 0x03 {
-		height := int(buf[4])*256 + int(buf[5])
-		// Hack to round the value
-		return uint8(math.Round(float64(height / 10.0)))
-	}
-	return 0
-}
-
-// readHeightRange decodes the height range limits from the controller response.
-// This handles the response from the FetchHeightRange command, extracting both
-// the maximum and minimum height limits that the desk can physically reach.
-//
-// Parameters:
-//   - buf: Response buffer from the controller. Expected format:
-//     [0xf2, 0xf2, type, 0x04, highestHighByte, highestLowByte,
-//     lowestHighByte, lowestLowByte, ..., checksum, 0x7e]
-//
-// Returns:
-//   - highestHeight: Maximum reachable height in centimeters
-//   - lowestHeight: Minimum reachable height in centimeters
-//   - (0, 0) if the response type is invalid
-func readHeightRange(buf []byte) (uint8, uint8) {
-	if buf[3] == 0x04 {
-		highestHeight := int(buf[4])*256 + int(buf[5])
-		lowestHeight := int(buf[6])*256 + int(buf[7])
-		return uint8(math.Round(float64(highestHeight / 10.0))),
-			uint8(math.Round(float64(lowestHeight / 10.0)))
+	for _, command := range queries {
+		if err := j.sendCommand(commands[command]); err != nil {
+			return err
+		}
+		if err := j.sendCommand(commands[command]); err != nil {
+			return err
+		}
 	}
-	return 0, 0
+	return nil
 }
@@ -1,31 +1,76 @@
 package jiecang
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestReadHeight(t *testing.T) {
+func TestReceiveHeight(t *testing.T) {
 	tests := []struct {
 		name           string // Name of the testcase
 		input          []byte // Input
-		expectedHeight uint8  // Expected result of function
+		expectedHeight Height // Expected result of function
 	}{
 		{
 			name:           "Valid message for height",
 			input:          []byte{0xf2, 0xf2, 0x01, 0x03, 0x03, 0x37, 0x07, 0x45, 0x7e},
-			expectedHeight: 82,
+			expectedHeight: 823,
 		},
 		{
-			name:           "Correct height with incorrect checksum",
+			name:           "Height with incorrect checksum is ignored",
 			input:          []byte{0xf2, 0xf2, 0x01, 0x03, 0x03, 0x37, 0x07, 0x46, 0x7e},
-			expectedHeight: 82,
+			expectedHeight: 0,
 		},
 	}
 
 	for _, test := range tests {
-		result := readHeight(test.input)
-		assert.Equal(t, test.expectedHeight, result, test.name)
+		ft := newFakeTransport()
+		j, err := New(ft)
+		require.NoError(t, err)
+		ft.notify(test.input)
+		assert.Equal(t, test.expectedHeight, j.currentHeight, test.name)
 	}
 }
+
+func TestGoToHeight(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	ft.notify(reply(0x07, 0x04, 0xf8, 0x02, 0x6c))
+	ft.notify(heightReply(823))
+
+	// Controller reaches the requested height as soon as it is asked to
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == 0x1b {
+			f.notify(heightReply(Height(frame[4])<<8 | Height(frame[5])))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := j.GoToHeight(ctx, Centimeters(107.5))
+	require.NoError(t, err)
+	assert.Equal(t, Arrived, res.Outcome)
+	assert.Equal(t, Height(1075), res.Height)
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	assert.Equal(t, Height(1075), j.currentHeight)
+	assert.Equal(t, 1, ft.count([]byte{0xf1, 0xf1, 0x1b, 0x02, 0x04, 0x33, 0x54, 0x7e}))
+}
+
+func TestGoToHeightOutOfRange(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	ft.notify(reply(0x07, 0x04, 0xf8, 0x02, 0x6c))
+
+	_, err = j.GoToHeight(context.Background(), 130*Centimeter)
+	assert.Error(t, err)
+	_, err = j.GoToHeight(context.Background(), 50*Centimeter)
+	assert.Error(t, err)
+}
@@ -1,11 +1,13 @@
 // Package jiecang provides control functionality for Jiecang standing desk controllers
 // via Bluetooth Low Energy (BLE) communication using the Lierda LSD4BT-E95ASTD001 module.
 //
-// The package implements the Jiecang UART protocol over BLE, supporting:
+// The package implements the Jiecang UART protocol over a pluggable Transport
+// (BLETransport by default), supporting:
 //   - Height control (up/down, go to specific height)
 //   - Memory presets (save and recall positions)
 //   - Height range queries
 //   - Desk settings (memory mode, anti-collision sensitivity)
+//   - Event subscriptions and automatic reconnection (Subscribe, Supervise)
 //
 // Example usage:
 //
@@ -23,32 +25,40 @@
 //	// Move desk to 100cm height with timeout
 //	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 //	defer cancel()
-//	desk.GoToHeight(ctx, 100)
+//	desk.GoToHeight(ctx, 100*jiecang.Centimeter)
 package jiecang
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
+	"time"
 
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
 	"tinygo.org/x/bluetooth"
 )
 
-var commands = map[string][]byte{
-	"up":                 {0xf1, 0xf1, 0x01, 0x00, 0x01, 0x7e},
-	"down":               {0xf1, 0xf1, 0x02, 0x00, 0x02, 0x7e},
-	"save_memory1":       {0xf1, 0xf1, 0x03, 0x00, 0x03, 0x7e},
-	"save_memory2":       {0xf1, 0xf1, 0x04, 0x00, 0x04, 0x7e},
-	"goto_memory1":       {0xf1, 0xf1, 0x05, 0x00, 0x05, 0x7e},
-	"goto_memory2":       {0xf1, 0xf1, 0x06, 0x00, 0x06, 0x7e},
-	"fetch_height":       {0xf1, 0xf1, 0x07, 0x00, 0x07, 0x7e},
-	"fetch_height_range": {0xf1, 0xf1, 0x0c, 0x00, 0x0c, 0x7e},
-	"save_memory3":       {0xf1, 0xf1, 0x25, 0x00, 0x25, 0x7e},
-	"goto_memory3":       {0xf1, 0xf1, 0x27, 0x00, 0x27, 0x7e},
-	"stop":               {0xf1, 0xf1, 0x2b, 0x00, 0x27, 0x7e},
-	"fetch_stand_time":   {0xf1, 0xf1, 0xa2, 0x00, 0xa2, 0x7e},
-	"fetch_all_time":     {0xf1, 0xf1, 0xaa, 0x00, 0xaa, 0x7e},
+var commands = map[string]protocol.Frame{
+	"up":                 {Type: protocol.CmdUp},
+	"down":               {Type: protocol.CmdDown},
+	"save_memory1":       {Type: protocol.CmdSaveMemory1},
+	"save_memory2":       {Type: protocol.CmdSaveMemory2},
+	"goto_memory1":       {Type: protocol.CmdGoToMemory1},
+	"goto_memory2":       {Type: protocol.CmdGoToMemory2},
+	"fetch_height":       {Type: protocol.CmdFetchSettings},
+	"fetch_height_range": {Type: protocol.CmdFetchHeightRange},
+	"save_memory3":       {Type: protocol.CmdSaveMemory3},
+	"goto_memory3":       {Type: protocol.CmdGoToMemory3},
+	"save_memory4":       {Type: protocol.CmdSaveMemory4},
+	"goto_memory4":       {Type: protocol.CmdGoToMemory4},
+	"stop":               {Type: protocol.CmdStop},
+	"fetch_stand_time":   {Type: protocol.CmdFetchStandTime},
+	"fetch_all_time":     {Type: protocol.CmdFetchAllTime},
+	"query_limits":       {Type: protocol.CmdQueryLimits},
+	"set_max_limit":      {Type: protocol.CmdSetMaxLimit},
+	"set_min_limit":      {Type: protocol.CmdSetMinLimit},
+	"clear_limits":       {Type: protocol.CmdClearLimits},
 }
 
 const (
@@ -58,28 +68,65 @@ const (
 )
 
 // Jiecang represents a connection to a Jiecang desk controller.
-// It manages BLE communication and maintains the current state of the desk.
+// It manages communication over a Transport and maintains the current state
+// of the desk.
 //
 // The struct uses a mutex to protect concurrent access to shared state,
 // allowing safe use from multiple goroutines. Height values are stored
-// in centimeters for convenience.
+// in millimeters, the resolution of the controller.
 type Jiecang struct {
-	device  bluetooth.Device               // BLE device connection
-	dataIn  bluetooth.DeviceCharacteristic // Write characteristic for sending commands
-	dataOut bluetooth.DeviceCharacteristic // Read characteristic for receiving responses
+	transport   Transport    // Link to the controller (BLE, serial, ...)
+	transportMu sync.RWMutex // Protects transport, which is replaced on reconnection
+	frames      reassembler  // Extracts frames from received data
+	waiters     waiters      // Pending queries waiting for a notification
 
-	currentHeight uint8        // Current height in centimeters
+	linkErr   chan struct{} // Signals a failed write to the supervisor
+	done      chan struct{} // Closed by Disconnect
+	closeOnce sync.Once
+
+	healthInterval time.Duration // How often the supervisor checks the link
+	minBackoff     time.Duration // Initial delay between reconnection attempts
+	maxBackoff     time.Duration // Maximum delay between reconnection attempts
+
+	queryTimeout time.Duration // How long to wait for an answer before resending a query
+	queryRetries int           // How many times to resend an unanswered query
+
+	events      eventBus      // Subscribers to desk events
+	heightKnown bool          // Whether a height report was received yet
+	moving      bool          // Whether the desk is moving, based on height reports
+	motionTimer *time.Timer   // Fires when the height stops changing
+	motionIdle  time.Duration // How long the height has to stay the same to consider the desk stopped
+
+	stallTimeout time.Duration // How long the height may stay the same while moving to a target
+
+	logger   *slog.Logger // Diagnostics, see WithLogger
+	progress ProgressFunc // Progress of movements, see WithProgress
+	tracer   *tracer      // Protocol trace, see WithTrace
+	profile  *Profile     // Capabilities of the controller, see WithProfile
+
+	calibration int // Offset added to the reported heights in millimeters, see WithCalibration
+
+	currentHeight Height       // Current height
 	mu            sync.RWMutex // Protects concurrent access to shared state
 
-	presets map[string]uint8 // Memory presets (memory1-4) in centimeters
+	presets     map[string]Height // Memory presets (memory1-4)
+	memorySlots int               // Highest memory preset reported by the controller
 
-	// LowestHeight is the minimum height limit of the desk in centimeters.
+	displayUnits    Unit // Units used to print heights, see SetDisplayUnits
+	displayOverride bool // Whether displayUnits overrides Units
+
+	// LowestHeight is the minimum physical height of the desk.
 	// Set during initialization from the controller.
-	LowestHeight uint8
+	LowestHeight Height
 
-	// HighestHeight is the maximum height limit of the desk in centimeters.
+	// HighestHeight is the maximum physical height of the desk.
 	// Set during initialization from the controller.
-	HighestHeight uint8
+	HighestHeight Height
+
+	// UserLimits are the user-defined limits restricting the physical
+	// height range. Set during initialization from the controller, if
+	// supported. See AllowedRange for the resulting range.
+	UserLimits Limits
 
 	// MemoryConstantTouchMode indicates if memory mode requires constant touch.
 	// false = one-touch mode, true = constant touch mode.
@@ -88,19 +135,29 @@ type Jiecang struct {
 	// AntiCollisionSensitivity indicates the anti-collision sensitivity level.
 	// Valid values: 1 = High, 2 = Medium, 3 = Low
 	AntiCollisionSensitivity uint8
+
+	// Stats holds the usage statistics of the controller.
+	// Requested during initialization, see QueryUsageStats.
+	Stats UsageStats
+
+	// ArrivalTolerance is how far from the target height the desk can stop
+	// and still be considered arrived by GoToHeight and GoToMemory.
+	// Defaults to 2 mm.
+	ArrivalTolerance Height
+
+	// Units is the display units setting of the controller.
+	// Set during initialization from the controller, see SetUnits.
+	Units Unit
 }
 
 // Init initializes a connection to a Jiecang desk controller via Bluetooth.
 //
-// The function performs the following steps:
-//  1. Connects to the BLE device at the specified address
-//  2. Discovers the Jiecang service (0xFE60)
-//  3. Discovers data input/output characteristics (0xFE61, 0xFE62)
-//  4. Enables notifications for receiving responses
-//  5. Queries the desk for current height, height range, and memory presets
+// It connects to the device through a BLETransport and then hands it over to
+// New. See NewBLETransport and New for the individual steps.
 //
 // Returns an error if any step fails (connection, service discovery,
 // characteristic discovery, or initial queries).
+// The options are passed to New.
 //
 // Example:
 //
@@ -112,101 +169,135 @@ type Jiecang struct {
 //	    log.Fatal(err)
 //	}
 //	defer desk.Disconnect()
-func Init(a *bluetooth.Adapter, addr bluetooth.Address) (*Jiecang, error) {
-	j := new(Jiecang)
-
-	// Connect to BLE Device
-	d, err := a.Connect(addr, bluetooth.ConnectionParams{})
+func Init(a *bluetooth.Adapter, addr bluetooth.Address, opts ...Option) (*Jiecang, error) {
+	t, err := NewBLETransport(a, addr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to device: %w", err)
+		return nil, err
 	}
 
-	// Scan Services and characteristics
-	services, err := d.DiscoverServices([]bluetooth.UUID{
-		bluetooth.New16BitUUID(BLEDeviceId),
-	})
-
+	j, err := New(t, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to discover services: %w", err)
+		_ = t.Close()
+		return nil, err
 	}
+	return j, nil
+}
 
-	serviceFound := false
-	for _, service := range services {
-		if service.UUID() != bluetooth.New16BitUUID(BLEDeviceId) {
-			// Wrong service
-			continue
-		}
-		serviceFound = true
-
-		// Found the correct service
-		// Get a list of characteristics below the service
-		characteristics, err := service.DiscoverCharacteristics([]bluetooth.UUID{
-			bluetooth.New16BitUUID(BLECharDataInId),
-			bluetooth.New16BitUUID(BLECharDataOutId),
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to discover characteristics: %w", err)
-		}
-
-		if len(characteristics) < 2 {
-			return nil, fmt.Errorf("expected 2 characteristics, got %d", len(characteristics))
-		}
-
-		j.dataIn = characteristics[0]
-		j.dataOut = characteristics[1]
-		// Enable notifications on dataOut
-		err = j.dataOut.EnableNotifications(j.characteristicReceiver)
-		if err != nil {
-			return nil, fmt.Errorf("failed to enable notifications: %w", err)
-		}
+// New initializes a Jiecang controller on top of an already established
+// Transport.
+//
+// The function performs the following steps:
+//  1. Subscribes to the data received from the controller
+//  2. Queries the desk for height range and memory presets, waiting for the
+//     answers
+//  3. Queries the user-defined height limits, if the controller supports them
+//  4. Requests the usage statistics, if the controller supports them
+//
+// Returns an error if any step fails or the controller does not answer.
+// The transport is not closed on error.
+//
+// The controller is configured with opts, e.g. WithLogger and WithProgress.
+// See Supervise to keep the connection alive.
+func New(t Transport, opts ...Option) (*Jiecang, error) {
+	j := &Jiecang{
+		transport:      t,
+		presets:        make(map[string]Height),
+		queryTimeout:   defaultQueryTimeout,
+		queryRetries:   defaultQueryRetries,
+		motionIdle:     defaultMotionIdle,
+		stallTimeout:   defaultStallTimeout,
+		linkErr:        make(chan struct{}, 1),
+		done:           make(chan struct{}),
+		healthInterval: defaultHealthInterval,
+		minBackoff:     defaultMinBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		logger:         slog.New(discardHandler{}),
+
+		ArrivalTolerance: defaultArrivalTolerance,
 	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	j.frames.dropped = j.discarded
 
-	if !serviceFound {
-		return nil, fmt.Errorf("BLE service %04x not found", BLEDeviceId)
+	if err := t.Subscribe(j.characteristicReceiver); err != nil {
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
 	}
+	j.logger.Debug("subscribed to controller", "height", j.format(j.currentHeight))
 
-	// Read desk current height
-	result := []byte{}
-	_, err = j.dataOut.Read(result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read initial height: %w", err)
+	if err := j.refresh(context.Background()); err != nil {
+		return nil, err
 	}
-	log.Printf("Initial height: %d mm", j.currentHeight)
+	return j, nil
+}
 
+// refresh queries the state of the desk: memory presets, height range, user
+// limits and usage statistics.
+func (j *Jiecang) refresh(ctx context.Context) error {
 	//Fetch height memory presets
-	j.presets = make(map[string]uint8)
-	if err := j.FetchHeight(); err != nil {
-		return nil, fmt.Errorf("failed to fetch height: %w", err)
+	if _, err := j.QueryPresets(ctx); err != nil {
+		return fmt.Errorf("failed to fetch height: %w", err)
 	}
 
 	// Fetch desk low and high height
-	if err := j.FetchHeightRange(); err != nil {
-		return nil, fmt.Errorf("failed to fetch height range: %w", err)
+	if _, err := j.QueryHeightRange(ctx); err != nil {
+		return fmt.Errorf("failed to fetch height range: %w", err)
 	}
 
+	if err := j.queryLimitsIfSupported(ctx); err != nil {
+		return fmt.Errorf("failed to fetch limits: %w", err)
+	}
+
+	if j.supportsStats() != nil {
+		return nil
+	}
 	if err := j.FetchStandTime(); err != nil {
-		return nil, fmt.Errorf("failed to fetch stand time: %w", err)
+		return fmt.Errorf("failed to fetch stand time: %w", err)
 	}
 
 	if err := j.FetchAllTime(); err != nil {
-		return nil, fmt.Errorf("failed to fetch all time: %w", err)
+		return fmt.Errorf("failed to fetch all time: %w", err)
 	}
-
-	j.device = d
-	return j, nil
+	return nil
 }
 
-// Disconnect closes the BLE connection to the desk controller.
+// Disconnect closes the connection to the desk controller.
 // Should be called when done using the controller to free resources.
+// Channels returned by Subscribe are closed and Supervise returns.
 // Safe to call even if the connection is already closed.
 func (j *Jiecang) Disconnect() error {
-	return j.device.Disconnect()
+	j.closeOnce.Do(func() { close(j.done) })
+	j.mu.Lock()
+	if j.motionTimer != nil {
+		j.motionTimer.Stop()
+	}
+	j.mu.Unlock()
+	j.events.close()
+
+	j.transportMu.RLock()
+	defer j.transportMu.RUnlock()
+	return j.transport.Close()
 }
 
-// simple wrapper to bluetooth.WriteWithoutResponse
-func (j *Jiecang) sendCommand(buf []byte) error {
-	_, err := j.dataIn.WriteWithoutResponse(buf)
-	if err != nil {
+// sendCommand encodes f and writes it to the transport.
+// A failed write wakes up the supervisor, see Supervise.
+func (j *Jiecang) sendCommand(f protocol.Frame) error {
+	return j.write(f.Encode())
+}
+
+// write writes data to the transport as is.
+// A failed write wakes up the supervisor, see Supervise.
+func (j *Jiecang) write(data []byte) error {
+	j.transportMu.RLock()
+	t := j.transport
+	j.transportMu.RUnlock()
+
+	j.trace(TraceSent, data)
+	if err := t.Write(data); err != nil {
+		select {
+		case j.linkErr <- struct{}{}:
+		default:
+		}
 		return fmt.Errorf("failed to send command: %w", err)
 	}
 	return nil
@@ -214,6 +305,7 @@ func (j *Jiecang) sendCommand(buf []byte) error {
 
 // FetchStandTime requests the desk's standing time statistics from the controller.
 // The command is sent twice as required by the protocol for reliability.
+// The answer is stored in Stats, use QueryUsageStats to wait for it.
 // Returns an error if the command transmission fails.
 func (j *Jiecang) FetchStandTime() error {
 	if err := j.sendCommand(commands["fetch_stand_time"]); err != nil {
@@ -224,6 +316,7 @@ func (j *Jiecang) FetchStandTime() error {
 
 // FetchAllTime requests the desk's total usage time statistics from the controller.
 // The command is sent twice as required by the protocol for reliability.
+// The answer is stored in Stats, use QueryUsageStats to wait for it.
 // Returns an error if the command transmission fails.
 func (j *Jiecang) FetchAllTime() error {
 	if err := j.sendCommand(commands["fetch_all_time"]); err != nil {
@@ -232,61 +325,151 @@ func (j *Jiecang) FetchAllTime() error {
 	return j.sendCommand(commands["fetch_all_time"])
 }
 
+// discarded reports data received from the controller that the reassembler
+// skipped, e.g. a frame with a bad checksum, to the subscribers of frames.
+func (j *Jiecang) discarded(data []byte, err error) {
+	j.logger.Debug("discarded data", "data", fmt.Sprintf("%x", data), "err", err)
+	j.events.publish(FrameReceived{Raw: data, Err: err})
+}
+
+// characteristicReceiver handles the data received from the controller.
+// Data may contain partial or multiple messages, complete messages are
+// extracted by the reassembler, decoded and stored in the desk state.
+// Changes of the desk state are published to the event subscribers.
 func (j *Jiecang) characteristicReceiver(buf []byte) {
-	/* Data should always start with f2f2 (2 bytes) and end with 7e
-	3rd byte is type/command?
-	4th byte is length (in bytes) of returned data
-	bytes 5 etc are data.
-	Previous to last byte is the checksum.
-	*/
-
-	// Buffer might contain multiple messages
-	msg := bytes.SplitAfter(buf, []byte{0x7e})
-	for i := 0; i < len(msg)-1; i++ {
-		// Check that message has minimum required length before validation
-		if len(msg[i]) < 3 {
+	j.trace(TraceReceived, buf)
+	for _, frame := range j.frames.Write(buf) {
+		msg, err := protocol.Decode(frame)
+		if err != nil {
+			j.logger.Debug("invalid frame", "frame", fmt.Sprintf("%x", frame), "err", err)
+			j.events.publish(FrameReceived{Raw: frame, Err: err})
 			continue
 		}
 
-		if isValidData(msg[i]) {
-			switch msg[i][2] {
-			case 0x01: // Data contains height measurements
-				//f2 f2 01 03 03 37 07 45 7e
-				// Use mutex to set current height
-				j.mu.Lock()
-				j.currentHeight = readHeight(msg[i])
-				j.mu.Unlock()
-			case 0x07: // Data contains height range of desk
-				j.mu.Lock()
-				j.HighestHeight, j.LowestHeight = readHeightRange(msg[i])
-				j.mu.Unlock()
-			case 0x25, 0x26, 0x27, 0x28: // Data contains height for each memory preset (1-4). Memory 4 is currently 0
-				memory := int(msg[i][2] % 0x24)
-				memoryName := fmt.Sprintf("memory%d", memory)
-				j.mu.Lock()
-				j.presets[memoryName] = readMemoryPreset(msg[i])
-				j.mu.Unlock()
-			case 0x0e: // Data contains units setting
-				fmt.Printf("Unit settings: %x\n", msg[i][3])
-			case 0x17: // Unknonwn setting so far
-				continue
-			case 0x19: // Data contains memory mode setting
-				if msg[i][3] == 0x01 {
-					j.mu.Lock()
-					j.MemoryConstantTouchMode = true
-					j.mu.Unlock()
-				}
-			case 0x1b: // Data contains response from go to height command
-				continue
-			case 0x1d: // Data contains anti-collision sensitivity
-				j.mu.Lock()
-				j.AntiCollisionSensitivity = uint8(msg[i][3])
-				j.mu.Unlock()
-			default: // Any other case
-				log.Printf("Received: %x", msg[i])
+		var events []Event
+		switch m := msg.(type) {
+		case protocol.HeightReport:
+			height, err := j.FromController(m.Height)
+			if err != nil {
+				j.logger.Warn("invalid height", "err", err)
+				break
+			}
+			j.mu.Lock()
+			previous := j.currentHeight
+			j.currentHeight = height
+			events = j.trackMotion(previous, j.currentHeight)
+			j.mu.Unlock()
+		case protocol.HeightRange:
+			highest, err := j.FromController(m.Highest)
+			if err != nil {
+				j.logger.Warn("invalid height range", "err", err)
+				break
+			}
+			lowest, err := j.FromController(m.Lowest)
+			if err != nil {
+				j.logger.Warn("invalid height range", "err", err)
+				break
 			}
-		} else {
-			log.Printf("Received: %x", msg[i])
+			j.mu.Lock()
+			j.HighestHeight, j.LowestHeight = highest, lowest
+			j.mu.Unlock()
+		case protocol.MemoryPreset:
+			memoryName := fmt.Sprintf("memory%d", m.Slot)
+			height, err := j.savedFromController(m.Height)
+			if err != nil {
+				j.logger.Warn("invalid memory preset", "slot", m.Slot, "err", err)
+				break
+			}
+			j.mu.Lock()
+			if previous, ok := j.presets[memoryName]; !ok || previous != height {
+				events = append(events, PresetUpdated{Slot: m.Slot, Height: height})
+			}
+			j.presets[memoryName] = height
+			j.memorySlots = max(j.memorySlots, m.Slot)
+			j.mu.Unlock()
+		case protocol.Limits:
+			j.mu.Lock()
+			if !m.MaxSet {
+				j.UserLimits.Max = 0
+			}
+			if !m.MinSet {
+				j.UserLimits.Min = 0
+			}
+			j.mu.Unlock()
+		case protocol.MaxLimit:
+			height, err := j.savedFromController(m.Height)
+			if err != nil {
+				j.logger.Warn("invalid upper limit", "err", err)
+				break
+			}
+			j.mu.Lock()
+			j.UserLimits.Max = height
+			j.mu.Unlock()
+		case protocol.MinLimit:
+			height, err := j.savedFromController(m.Height)
+			if err != nil {
+				j.logger.Warn("invalid lower limit", "err", err)
+				break
+			}
+			j.mu.Lock()
+			j.UserLimits.Min = height
+			j.mu.Unlock()
+		case protocol.StandTime:
+			j.mu.Lock()
+			j.Stats.StandingTime = time.Duration(m.Minutes) * time.Minute
+			j.mu.Unlock()
+		case protocol.AllTime:
+			j.mu.Lock()
+			j.Stats.TotalTime = time.Duration(m.Minutes) * time.Minute
+			j.mu.Unlock()
+		case protocol.Units:
+			j.mu.Lock()
+			if j.Units != Unit(m.Unit) {
+				j.Units = Unit(m.Unit)
+				events = append(events, j.settings())
+			}
+			j.mu.Unlock()
+		case protocol.MemoryMode:
+			j.mu.Lock()
+			if j.MemoryConstantTouchMode != m.ConstantTouch {
+				j.MemoryConstantTouchMode = m.ConstantTouch
+				events = append(events, j.settings())
+			}
+			j.mu.Unlock()
+		case protocol.AntiCollision:
+			j.mu.Lock()
+			if j.AntiCollisionSensitivity != m.Sensitivity {
+				j.AntiCollisionSensitivity = m.Sensitivity
+				events = append(events, j.settings())
+			}
+			j.mu.Unlock()
+		case protocol.Unknown:
+			events = append(events, UnknownFrame{Frame: m.Frame})
+			switch m.Frame.Type {
+			case protocol.MsgUnknown17: // Unknown setting so far
+			case protocol.MsgGoToHeight: // Response from go to height command
+			default:
+				j.logger.Debug("unknown frame", "frame", fmt.Sprintf("%x", frame))
+			}
+		}
+
+		// Desk state is updated, wake up pending queries and subscribers
+		// Answers to link checks are not shown, see checkLink
+		if quiet := j.waiters.dispatch(msg); !quiet {
+			j.events.publish(FrameReceived{Raw: frame, Message: msg})
 		}
+		for _, e := range events {
+			j.events.publish(e)
+		}
+	}
+}
+
+// settings returns a SettingsChanged event with the current settings.
+// Must be called with j.mu held.
+func (j *Jiecang) settings() SettingsChanged {
+	return SettingsChanged{
+		MemoryConstantTouchMode:  j.MemoryConstantTouchMode,
+		AntiCollisionSensitivity: j.AntiCollisionSensitivity,
+		Units:                    j.Units,
 	}
 }
@@ -0,0 +1,187 @@
+package jiecang
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// fakeTransport is an in-memory Transport that records written frames and
+// lets tests reply with notifications from the controller.
+type fakeTransport struct {
+	mu      sync.Mutex
+	written [][]byte
+	handler func(buf []byte)
+	closed  bool
+
+	// responses holds the notifications sent back for each command type.
+	responses map[byte][][]byte
+
+	// onWrite, if set, is called for every written frame.
+	onWrite func(t *fakeTransport, frame []byte)
+
+	// err, if set, is returned by Write, like a lost link.
+	err error
+}
+
+// newFakeTransport returns a fakeTransport answering the queries sent
+// during initialization like a real controller would.
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{
+		responses: map[byte][][]byte{
+			protocol.CmdFetchHeightRange: {reply(0x07, 0x04, 0xf8, 0x02, 0x6c)},
+			protocol.CmdQueryLimits:      {reply(0x20, 0x00)},
+			protocol.CmdFetchStandTime:   {reply(0xa2, 0x00, 0x00, 0x00, 0x5a)},
+			protocol.CmdFetchAllTime:     {reply(0xaa, 0x00, 0x00, 0x46, 0x50)},
+			protocol.CmdFetchSettings: {
+				reply(0x25, 0x04, 0x4e),
+				reply(0x26, 0x02, 0xda),
+				reply(0x27, 0x00, 0x00),
+				reply(0x28, 0x00, 0x00),
+			},
+		},
+	}
+}
+
+func (f *fakeTransport) Write(frame []byte) error {
+	f.mu.Lock()
+	if f.err != nil {
+		f.mu.Unlock()
+		return f.err
+	}
+	f.written = append(f.written, append([]byte(nil), frame...))
+	onWrite := f.onWrite
+	responses := f.responses[frame[2]]
+	f.mu.Unlock()
+
+	for _, r := range responses {
+		f.notify(r)
+	}
+	if onWrite != nil {
+		onWrite(f, frame)
+	}
+	return nil
+}
+
+func (f *fakeTransport) Subscribe(handler func(buf []byte)) error {
+	f.handler = handler
+	return nil
+}
+
+func (f *fakeTransport) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+// fail makes every following write fail with err.
+func (f *fakeTransport) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// notify delivers buf to the subscribed handler as if it was received
+// from the controller.
+func (f *fakeTransport) notify(buf []byte) {
+	f.handler(buf)
+}
+
+// count returns how many times frame was written.
+func (f *fakeTransport) count(frame []byte) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, w := range f.written {
+		if bytes.Equal(w, frame) {
+			n++
+		}
+	}
+	return n
+}
+
+// reply builds a controller notification frame of the given type.
+func reply(dataType byte, data ...byte) []byte {
+	return protocol.Frame{Type: dataType, Payload: data}.EncodeNotification()
+}
+
+// heightReply builds a height notification.
+func heightReply(height Height) []byte {
+	return reply(0x01, byte(height/256), byte(height%256), 0x00)
+}
+
+func TestNew(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Queries are answered and not repeated
+	assert.Equal(t, 1, ft.count(commands["fetch_height"].Encode()))
+	assert.Equal(t, 1, ft.count(commands["fetch_height_range"].Encode()))
+	assert.Equal(t, 2, ft.count(commands["fetch_stand_time"].Encode()))
+	assert.Equal(t, 2, ft.count(commands["fetch_all_time"].Encode()))
+
+	j.mu.RLock()
+	assert.Equal(t, Height(620), j.LowestHeight)
+	assert.Equal(t, Height(1272), j.HighestHeight)
+	assert.Equal(t, Height(1102), j.presets["memory1"])
+	assert.Equal(t, Height(730), j.presets["memory2"])
+	j.mu.RUnlock()
+
+	require.NoError(t, j.Disconnect())
+	assert.True(t, ft.closed)
+}
+
+func TestCharacteristicReceiver(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Multiple messages in a single notification
+	buf := append(heightReply(823), reply(0x07, 0x04, 0xf8, 0x02, 0x6c)...)
+	buf = append(buf, reply(0x25, 0x04, 0x4e)...)
+	ft.notify(buf)
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	assert.Equal(t, Height(823), j.currentHeight)
+	assert.Equal(t, Height(1272), j.HighestHeight)
+	assert.Equal(t, Height(620), j.LowestHeight)
+	assert.Equal(t, Height(1102), j.presets["memory1"])
+}
+
+func TestCharacteristicReceiverFragmented(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Height report split across two notifications
+	buf := heightReply(1075)
+	ft.notify(buf[:4])
+	ft.notify(buf[4:])
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	assert.Equal(t, Height(1075), j.currentHeight)
+}
+
+func TestCharacteristicReceiverSettings(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	ft.notify(reply(0x19, 0x01))
+	ft.notify(reply(0x1d, 0x03))
+	ft.notify(reply(0x0e, 0x01))
+	assert.True(t, j.MemoryConstantTouchMode)
+	assert.Equal(t, uint8(3), j.AntiCollisionSensitivity)
+	assert.Equal(t, UnitInches, j.Units)
+
+	ft.notify(reply(0x19, 0x00))
+	assert.False(t, j.MemoryConstantTouchMode)
+}
@@ -0,0 +1,280 @@
+package jiecang
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// This file contains functions for the user-defined height limits, which
+// restrict the physical height range of the desk (e.g. to keep it from
+// hitting a shelf above it).
+
+// Limits holds the user-defined height limits of the desk. A zero height
+// means that the limit is not set.
+type Limits struct {
+	Min Height
+	Max Height
+}
+
+// AllowedRange returns the range the desk can move in: the physical height
+// range, restricted by the user limits that are set.
+func (j *Jiecang) AllowedRange() Range {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	r := Range{Lowest: j.LowestHeight, Highest: j.HighestHeight}
+	if j.UserLimits.Min != 0 && j.UserLimits.Min > r.Lowest {
+		r.Lowest = j.UserLimits.Min
+	}
+	if j.UserLimits.Max != 0 && j.UserLimits.Max < r.Highest {
+		r.Highest = j.UserLimits.Max
+	}
+	return r
+}
+
+// QueryLimits requests the user-defined height limits and waits for the
+// answer. UserLimits is updated as well.
+//
+// The controller first reports which limits are set, then the height of
+// each of them.
+//
+// Returns an error if the command transmission fails, the controller does
+// not answer (ErrTimeout) or ctx is cancelled. Fails with ErrUnsupported if
+// the profile of the controller does not support limits, see WithProfile.
+func (j *Jiecang) QueryLimits(ctx context.Context) (Limits, error) {
+	if err := j.supportsLimits(); err != nil {
+		return Limits{}, err
+	}
+
+	// Register before sending, so the heights following the flags are not
+	// missed.
+	wt := j.waiters.add(func(m protocol.Message) bool {
+		switch m.(type) {
+		case protocol.MaxLimit, protocol.MinLimit:
+			return true
+		}
+		return false
+	})
+	defer j.waiters.remove(wt)
+
+	msg, err := j.request(ctx, commands["query_limits"], func(m protocol.Message) bool {
+		_, ok := m.(protocol.Limits)
+		return ok
+	})
+	if err != nil {
+		return Limits{}, err
+	}
+
+	flags := msg.(protocol.Limits)
+	var limits Limits
+	for (flags.MaxSet && limits.Max == 0) || (flags.MinSet && limits.Min == 0) {
+		select {
+		case m := <-wt.ch:
+			var err error
+			switch m := m.(type) {
+			case protocol.MaxLimit:
+				limits.Max, err = j.savedFromController(m.Height)
+			case protocol.MinLimit:
+				limits.Min, err = j.savedFromController(m.Height)
+			}
+			if err != nil {
+				return Limits{}, err
+			}
+		case <-ctx.Done():
+			return Limits{}, ctx.Err()
+		case <-time.After(j.queryTimeout):
+			return Limits{}, fmt.Errorf("limit heights: %w", ErrTimeout)
+		}
+	}
+	return limits, nil
+}
+
+// SetMaxLimit sets the current height as the upper limit of the desk and
+// waits for the controller to confirm it. Returns the new limit.
+//
+// Returns an error if the command transmission fails, the controller does
+// not confirm the limit (ErrTimeout) or ctx is cancelled.
+func (j *Jiecang) SetMaxLimit(ctx context.Context) (Height, error) {
+	if err := j.supportsLimits(); err != nil {
+		return 0, err
+	}
+	msg, err := j.request(ctx, commands["set_max_limit"], func(m protocol.Message) bool {
+		_, ok := m.(protocol.MaxLimit)
+		return ok
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to set upper limit: %w", err)
+	}
+	return j.savedFromController(msg.(protocol.MaxLimit).Height)
+}
+
+// SetMinLimit sets the current height as the lower limit of the desk and
+// waits for the controller to confirm it. Returns the new limit.
+//
+// Returns an error if the command transmission fails, the controller does
+// not confirm the limit (ErrTimeout) or ctx is cancelled.
+func (j *Jiecang) SetMinLimit(ctx context.Context) (Height, error) {
+	if err := j.supportsLimits(); err != nil {
+		return 0, err
+	}
+	msg, err := j.request(ctx, commands["set_min_limit"], func(m protocol.Message) bool {
+		_, ok := m.(protocol.MinLimit)
+		return ok
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to set lower limit: %w", err)
+	}
+	return j.savedFromController(msg.(protocol.MinLimit).Height)
+}
+
+// ClearLimits clears the user-defined height limits and waits for the
+// controller to confirm it. The desk can then move in its whole physical
+// height range again.
+//
+// Returns an error if the command transmission fails, the controller does
+// not confirm (ErrTimeout) or ctx is cancelled.
+func (j *Jiecang) ClearLimits(ctx context.Context) error {
+	if err := j.supportsLimits(); err != nil {
+		return err
+	}
+	_, err := j.request(ctx, commands["clear_limits"], func(m protocol.Message) bool {
+		l, ok := m.(protocol.Limits)
+		return ok && !l.MaxSet && !l.MinSet
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear limits: %w", err)
+	}
+	return nil
+}
+
+// SetLimits programs the user-defined height limits into the controller.
+// A zero height keeps the respective limit as it is, set or not; use
+// ClearLimits to remove the limits.
+//
+// The controller sets a limit at the current height and can only clear both
+// limits at once, so the desk is moved to each limit in turn: the previous
+// limits are cleared, the desk moves to the upper limit and then to the
+// lower one, the new or kept ones, and finally back to its initial height if
+// it is within the new limits.
+//
+// Returns an error if the current limits cannot be queried, the limits are
+// out of the physical height range, the lower limit is not below the upper
+// one, a movement or command fails, or ctx is cancelled. Fails with ErrUnsupported if the profile of the
+// controller does not support limits, see WithProfile.
+//
+// Example:
+//
+//	err := desk.SetLimits(ctx, 70*jiecang.Centimeter, 115*jiecang.Centimeter)
+func (j *Jiecang) SetLimits(ctx context.Context, lower, upper Height) error {
+	if err := j.supportsLimits(); err != nil {
+		return err
+	}
+
+	// The limits may not be known yet, see queryLimitsIfSupported
+	current, err := j.QueryLimits(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query limits: %w", err)
+	}
+
+	j.mu.RLock()
+	lowest, highest, initial := j.LowestHeight, j.HighestHeight, j.currentHeight
+	j.mu.RUnlock()
+
+	for _, h := range []Height{lower, upper} {
+		if h != 0 && (h < lowest || h > highest) {
+			return fmt.Errorf("limit %s is out of range (low: %s, high: %s)", j.format(h), j.format(lowest), j.format(highest))
+		}
+	}
+	// Keep the limits that are not replaced
+	if lower == 0 {
+		lower = current.Min
+	}
+	if upper == 0 {
+		upper = current.Max
+	}
+	if lower != 0 && upper != 0 && lower >= upper {
+		return fmt.Errorf("lower limit %s must be below upper limit %s", j.format(lower), j.format(upper))
+	}
+
+	// The current limits would keep the desk from reaching the new ones
+	if current.Min != 0 || current.Max != 0 {
+		if err := j.ClearLimits(ctx); err != nil {
+			return err
+		}
+	}
+
+	if upper != 0 {
+		if err := j.moveAndSetLimit(ctx, upper, j.SetMaxLimit); err != nil {
+			return err
+		}
+	}
+	if lower != 0 {
+		if err := j.moveAndSetLimit(ctx, lower, j.SetMinLimit); err != nil {
+			return err
+		}
+	}
+
+	if r := j.AllowedRange(); initial >= r.Lowest && initial <= r.Highest {
+		if err := j.moveTo(ctx, initial); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveAndSetLimit moves the desk to height and sets it as a limit with set.
+func (j *Jiecang) moveAndSetLimit(ctx context.Context, height Height, set func(context.Context) (Height, error)) error {
+	if err := j.moveTo(ctx, height); err != nil {
+		return err
+	}
+	_, err := set(ctx)
+	return err
+}
+
+// moveTo moves the desk to height, returning an error unless it arrives.
+func (j *Jiecang) moveTo(ctx context.Context, height Height) error {
+	res, err := j.GoToHeight(ctx, height)
+	if err != nil {
+		return err
+	}
+	if res.Outcome == Cancelled {
+		return ctx.Err()
+	}
+	if res.Outcome != Arrived {
+		return fmt.Errorf("desk did not reach %s: %s at %s", j.format(height), res.Outcome, j.format(res.Height))
+	}
+	return nil
+}
+
+// supportsLimits returns an error wrapping ErrUnsupported if the controller
+// does not support user-defined limits according to its profile.
+func (j *Jiecang) supportsLimits() error {
+	return j.supports("user-defined height limits", func(p Profile) bool { return p.Limits })
+}
+
+// queryLimitsIfSupported queries the user limits, which are not supported
+// by every controller. The answer is only waited for if the profile of the
+// controller supports limits. Without a profile the limits are requested
+// without waiting, so that a controller that never answers does not delay
+// every connection, and stored if an answer arrives. A controller that does
+// not answer, or whose profile does not support limits, is not an error.
+func (j *Jiecang) queryLimitsIfSupported(ctx context.Context) error {
+	if j.supportsLimits() != nil {
+		return nil
+	}
+	if j.profile == nil {
+		return j.sendCommand(commands["query_limits"])
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, j.queryTimeout)
+	defer cancel()
+
+	_, err := j.QueryLimits(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package jiecang
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestQueryLimits(t *testing.T) {
+	ft := newFakeTransport()
+	ft.responses[protocol.CmdQueryLimits] = [][]byte{
+		reply(0x20, 0x11),
+		reply(0x21, 0x04, 0x7e),
+		reply(0x22, 0x02, 0xbc),
+	}
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	limits, err := j.QueryLimits(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Limits{Min: 700, Max: 1150}, limits)
+	assert.Equal(t, Limits{Min: 700, Max: 1150}, j.UserLimits)
+
+	// Physical range is kept apart from the user limits
+	assert.Equal(t, Height(620), j.LowestHeight)
+	assert.Equal(t, Height(1272), j.HighestHeight)
+	assert.Equal(t, Range{Lowest: 700, Highest: 1150}, j.AllowedRange())
+	_, err = j.GoToHeight(context.Background(), 120*Centimeter)
+	assert.Error(t, err)
+}
+
+func TestNewWithoutLimits(t *testing.T) {
+	ft := newFakeTransport()
+	delete(ft.responses, protocol.CmdQueryLimits)
+	start := time.Now()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Limits are requested without waiting for an answer
+	assert.Less(t, time.Since(start), j.queryTimeout)
+	assert.Equal(t, 1, ft.count(commands["query_limits"].Encode()))
+	assert.Equal(t, Limits{}, j.UserLimits)
+	assert.Equal(t, Range{Lowest: 620, Highest: 1272}, j.AllowedRange())
+}
+
+func TestSetAndClearLimits(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	ft.notify(heightReply(1100))
+
+	ft.responses[protocol.CmdSetMaxLimit] = [][]byte{reply(0x21, 0x04, 0x4c)}
+	ft.responses[protocol.CmdSetMinLimit] = [][]byte{reply(0x22, 0x04, 0x4c)}
+	ft.responses[protocol.CmdClearLimits] = [][]byte{reply(0x20, 0x00)}
+
+	height, err := j.SetMaxLimit(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Height(1100), height)
+	assert.Equal(t, Limits{Max: 1100}, j.UserLimits)
+
+	height, err = j.SetMinLimit(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Height(1100), height)
+	assert.Equal(t, Limits{Min: 1100, Max: 1100}, j.UserLimits)
+
+	require.NoError(t, j.ClearLimits(context.Background()))
+	assert.Equal(t, Limits{}, j.UserLimits)
+}
+
+func TestSetLimitsInvalid(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	assert.Error(t, j.SetLimits(context.Background(), 50*Centimeter, 0))
+	assert.Error(t, j.SetLimits(context.Background(), 0, 130*Centimeter))
+	assert.Error(t, j.SetLimits(context.Background(), 110*Centimeter, 100*Centimeter))
+	assert.Zero(t, ft.count(commands["clear_limits"].Encode()))
+}
@@ -0,0 +1,113 @@
+package jiecang
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// This file contains the manual movements, which emulate holding the up or
+// down button of the desk control panel.
+
+// Direction is the direction of a manual movement.
+type Direction int
+
+const (
+	DirectionUp Direction = iota + 1
+	DirectionDown
+)
+
+// String returns the name of d.
+func (d Direction) String() string {
+	switch d {
+	case DirectionUp:
+		return "up"
+	case DirectionDown:
+		return "down"
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// MoveFor moves the desk in direction dir for duration d, like holding the
+// up or down button, then stops it.
+//
+// Returns a MoveResult with the final height. The outcome is Arrived once
+// d elapsed or the desk reached the end of its allowed range, Stalled or
+// CollisionSuspected if the desk stopped on its own, and Cancelled if ctx
+// is done first. Target is the end of the allowed range in direction dir.
+//
+// Returns an error if dir is not valid or command transmission fails.
+//
+// Example:
+//
+//	res, err := desk.MoveFor(ctx, jiecang.DirectionUp, 2*time.Second)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("Desk at %s\n", res.Height)
+func (j *Jiecang) MoveFor(ctx context.Context, dir Direction, d time.Duration) (MoveResult, error) {
+	r := j.AllowedRange()
+	target := r.Highest
+	if dir == DirectionDown {
+		target = r.Lowest
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	return j.hold(ctx, dir, target, timer.C)
+}
+
+// MoveBy moves the desk by delta millimeters, up if delta is positive and
+// down otherwise, like holding the up or down button until the desk gets
+// there. The desk may coast a few millimeters after being stopped.
+//
+// Returns a MoveResult with the outcome of the movement and the final
+// height, see GoToHeight.
+//
+// Returns an error if delta is 0, the target height is out of the allowed
+// range or command transmission fails.
+//
+// Example:
+//
+//	// Raise the desk by 5 mm
+//	if _, err := desk.MoveBy(ctx, 5); err != nil {
+//	    log.Fatal(err)
+//	}
+func (j *Jiecang) MoveBy(ctx context.Context, delta int) (MoveResult, error) {
+	if delta == 0 {
+		return MoveResult{}, fmt.Errorf("distance must not be 0")
+	}
+
+	j.mu.RLock()
+	target := int(j.currentHeight) + delta
+	j.mu.RUnlock()
+
+	r := j.AllowedRange()
+	if target > int(r.Highest) || target < int(r.Lowest) {
+		return MoveResult{}, fmt.Errorf("moving by %d mm is out of range (low: %s, high: %s)", delta, j.format(r.Lowest), j.format(r.Highest))
+	}
+
+	dir := DirectionUp
+	if delta < 0 {
+		dir = DirectionDown
+	}
+	return j.hold(ctx, dir, Height(target), nil)
+}
+
+// hold repeats the up or down command, like a held button, until the desk
+// reaches target, deadline fires, the desk stalls or reverses, or ctx is
+// done. The desk is then stopped and given time to settle.
+func (j *Jiecang) hold(ctx context.Context, dir Direction, target Height, deadline <-chan time.Time) (MoveResult, error) {
+	var command protocol.Frame
+	switch dir {
+	case DirectionUp:
+		command = commands["up"]
+	case DirectionDown:
+		command = commands["down"]
+	default:
+		return MoveResult{}, fmt.Errorf("invalid direction %s", dir)
+	}
+	return j.move(ctx, command, target, moveOptions{deadline: deadline, hold: true})
+}
@@ -0,0 +1,127 @@
+package jiecang
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// holdingTransport returns a fakeTransport whose desk moves by step every
+// time a movement command is received, starting from height.
+func holdingTransport(height Height, step Height) *fakeTransport {
+	var mu sync.Mutex
+	ft := newFakeTransport()
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		mu.Lock()
+		switch frame[2] {
+		case protocol.CmdUp:
+			height += step
+		case protocol.CmdDown:
+			height -= step
+		default:
+			mu.Unlock()
+			return
+		}
+		h := height
+		mu.Unlock()
+		f.notify(heightReply(h))
+	}
+	return ft
+}
+
+func TestMoveBy(t *testing.T) {
+	tests := []struct {
+		name           string // Name of the testcase
+		delta          int    // Input
+		expectedHeight Height // Expected final height
+		expectedDown   bool   // Whether the desk is expected to move down
+	}{
+		{
+			name:           "Move up",
+			delta:          50,
+			expectedHeight: 850,
+		},
+		{
+			name:           "Move down",
+			delta:          -30,
+			expectedHeight: 770,
+			expectedDown:   true,
+		},
+		{
+			name:           "Target passed between two reports",
+			delta:          45,
+			expectedHeight: 850,
+		},
+	}
+
+	for _, test := range tests {
+		ft := holdingTransport(800, 10)
+		j, err := New(ft)
+		require.NoError(t, err, test.name)
+		ft.notify(heightReply(800))
+
+		res, err := j.MoveBy(context.Background(), test.delta)
+		require.NoError(t, err, test.name)
+		assert.Equal(t, Arrived, res.Outcome, test.name)
+		assert.Equal(t, test.expectedHeight, res.Height, test.name)
+		assert.Equal(t, 1, ft.count(commands["stop"].Encode()), test.name)
+		if test.expectedDown {
+			assert.Equal(t, 0, ft.count(commands["up"].Encode()), test.name)
+		} else {
+			assert.Equal(t, 0, ft.count(commands["down"].Encode()), test.name)
+		}
+	}
+}
+
+func TestMoveByOutOfRange(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	ft.notify(heightReply(800))
+
+	_, err = j.MoveBy(context.Background(), 0)
+	assert.Error(t, err)
+	_, err = j.MoveBy(context.Background(), 500)
+	assert.Error(t, err)
+	_, err = j.MoveBy(context.Background(), -200)
+	assert.Error(t, err)
+}
+
+func TestMoveFor(t *testing.T) {
+	ft := holdingTransport(800, 1)
+	j, err := New(ft)
+	require.NoError(t, err)
+	ft.notify(heightReply(800))
+
+	res, err := j.MoveFor(context.Background(), DirectionUp, 500*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, Arrived, res.Outcome)
+	assert.Greater(t, res.Height, Height(800))
+	assert.Equal(t, Height(1272), res.Target)
+	// The command is repeated while the button is held
+	assert.GreaterOrEqual(t, ft.count(commands["up"].Encode()), 2)
+	assert.Equal(t, 1, ft.count(commands["stop"].Encode()))
+}
+
+func TestMoveForStalled(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	j.stallTimeout = 300 * time.Millisecond
+	ft.notify(heightReply(800))
+
+	// Desk does not move
+	res, err := j.MoveFor(context.Background(), DirectionDown, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, Stalled, res.Outcome)
+	assert.Equal(t, Height(800), res.Height)
+	assert.Equal(t, 1, ft.count(commands["stop"].Encode()))
+
+	_, err = j.MoveFor(context.Background(), Direction(0), time.Second)
+	assert.Error(t, err)
+}
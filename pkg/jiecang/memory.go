@@ -3,70 +3,72 @@ package jiecang
 import (
 	"context"
 	"fmt"
-	"log"
-	"math"
 	"time"
 )
 
-// GoToMemory moves the desk to the specified memory preset (1-3).
-// The operation polls the current height until the target preset height is reached
-// or the context is cancelled.
+// defaultMemorySlots is the number of memory presets assumed when the
+// controller did not report any.
+const defaultMemorySlots = 3
+
+// MemoryPresets returns the number of memory presets of the controller:
+// 4 if it reports memory preset 4, even as not set, 3 otherwise. The
+// profile of the controller takes precedence, see WithProfile.
+func (j *Jiecang) MemoryPresets() int {
+	if j.profile != nil && j.profile.MemoryPresets > 0 {
+		return j.profile.MemoryPresets
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return max(j.memorySlots, defaultMemorySlots)
+}
+
+// checkMemoryNum returns an error if the controller has no memory preset
+// memoryNum.
+func (j *Jiecang) checkMemoryNum(memoryNum int) error {
+	if n := j.MemoryPresets(); memoryNum < 1 || memoryNum > n {
+		return fmt.Errorf("invalid memory number %d (must be 1-%d)", memoryNum, n)
+	}
+	return nil
+}
+
+// GoToMemory moves the desk to the specified memory preset (1-4), following
+// the movement like GoToHeight.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
-//   - memoryNum: Memory preset number (1, 2, or 3)
+//   - memoryNum: Memory preset number (1-3, or 1-4 if the controller
+//     supports memory preset 4, see MemoryPresets)
+//
+// Returns a MoveResult with the outcome of the movement and the final
+// height.
 //
 // Returns an error if:
-//   - memoryNum is not in the valid range (1-3)
+//   - memoryNum is not a memory preset of the controller
+//   - the memory preset is not set
 //   - command transmission fails
-//   - context is cancelled (operation stops gracefully, returns nil)
 //
 // Example:
 //
 //	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 //	defer cancel()
-//	if err := desk.GoToMemory(ctx, 1); err != nil {
+//	if _, err := desk.GoToMemory(ctx, 1); err != nil {
 //	    log.Fatal(err)
 //	}
-func (j *Jiecang) GoToMemory(ctx context.Context, memoryNum int) error {
-	if memoryNum < 1 || memoryNum > 3 {
-		return fmt.Errorf("invalid memory number %d (must be 1-3)", memoryNum)
+func (j *Jiecang) GoToMemory(ctx context.Context, memoryNum int) (MoveResult, error) {
+	if err := j.checkMemoryNum(memoryNum); err != nil {
+		return MoveResult{}, err
 	}
 
-	commandKey := fmt.Sprintf("goto_memory%d", memoryNum)
-	memoryKey := fmt.Sprintf("memory%d", memoryNum)
-
-	// Send the command twice the first time
-	if err := j.sendCommand(commands[commandKey]); err != nil {
-		return fmt.Errorf("failed to send goto memory%d command: %w", memoryNum, err)
+	// An unset preset is reported as 0, which the desk never reaches
+	j.mu.RLock()
+	preset := j.presets[fmt.Sprintf("memory%d", memoryNum)]
+	j.mu.RUnlock()
+	if preset == 0 {
+		return MoveResult{}, fmt.Errorf("memory %d is not set", memoryNum)
 	}
 
-	ticker := time.NewTicker(200 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		j.mu.RLock()
-		currentHeight := j.currentHeight
-		targetHeight := j.presets[memoryKey]
-		j.mu.RUnlock()
-
-		if currentHeight == targetHeight {
-			fmt.Printf("\rHeight: %d cm\n", currentHeight)
-			break
-		}
-
-		select {
-		case <-ctx.Done():
-			fmt.Printf("\nOperation cancelled at height %d cm\n", currentHeight)
-			return nil
-		case <-ticker.C:
-			fmt.Printf("\rHeight: %d cm", currentHeight)
-			if err := j.sendCommand(commands[commandKey]); err != nil {
-				return fmt.Errorf("failed to send goto memory%d command: %w", memoryNum, err)
-			}
-		}
-	}
-	return nil
+	return j.move(ctx, commands[fmt.Sprintf("goto_memory%d", memoryNum)], preset, moveOptions{})
 }
 
 // GoToMemory1 moves the desk to the height saved in memory preset 1.
@@ -77,7 +79,8 @@ func (j *Jiecang) GoToMemory(ctx context.Context, memoryNum int) error {
 //
 // Returns an error if command transmission fails or the context is cancelled.
 func (j *Jiecang) GoToMemory1(ctx context.Context) error {
-	return j.GoToMemory(ctx, 1)
+	_, err := j.GoToMemory(ctx, 1)
+	return err
 }
 
 // GoToMemory2 moves the desk to the height saved in memory preset 2.
@@ -88,7 +91,8 @@ func (j *Jiecang) GoToMemory1(ctx context.Context) error {
 //
 // Returns an error if command transmission fails or the context is cancelled.
 func (j *Jiecang) GoToMemory2(ctx context.Context) error {
-	return j.GoToMemory(ctx, 2)
+	_, err := j.GoToMemory(ctx, 2)
+	return err
 }
 
 // GoToMemory3 moves the desk to the height saved in memory preset 3.
@@ -99,18 +103,20 @@ func (j *Jiecang) GoToMemory2(ctx context.Context) error {
 //
 // Returns an error if command transmission fails or the context is cancelled.
 func (j *Jiecang) GoToMemory3(ctx context.Context) error {
-	return j.GoToMemory(ctx, 3)
+	_, err := j.GoToMemory(ctx, 3)
+	return err
 }
 
-// SaveMemory saves the current desk height to the specified memory preset (1-3).
+// SaveMemory saves the current desk height to the specified memory preset (1-4).
 // The current height is stored in the controller's non-volatile memory
 // and can be recalled later using GoToMemory.
 //
 // Parameters:
-//   - memoryNum: Memory preset number (1, 2, or 3)
+//   - memoryNum: Memory preset number (1-3, or 1-4 if the controller
+//     supports memory preset 4, see MemoryPresets)
 //
 // Returns an error if:
-//   - memoryNum is not in the valid range (1-3)
+//   - memoryNum is not a memory preset of the controller
 //   - command transmission fails
 //
 // Example:
@@ -119,8 +125,8 @@ func (j *Jiecang) GoToMemory3(ctx context.Context) error {
 //	    log.Fatal(err)
 //	}
 func (j *Jiecang) SaveMemory(memoryNum int) error {
-	if memoryNum < 1 || memoryNum > 3 {
-		return fmt.Errorf("invalid memory number %d (must be 1-3)", memoryNum)
+	if err := j.checkMemoryNum(memoryNum); err != nil {
+		return err
 	}
 
 	commandKey := fmt.Sprintf("save_memory%d", memoryNum)
@@ -128,7 +134,10 @@ func (j *Jiecang) SaveMemory(memoryNum int) error {
 		return fmt.Errorf("failed to save memory%d: %w", memoryNum, err)
 	}
 
-	log.Printf("Saved height %d cm to memory %d", j.currentHeight, memoryNum)
+	j.mu.RLock()
+	height := j.currentHeight
+	j.mu.RUnlock()
+	j.logger.Info("saved memory preset", "memory", memoryNum, "height", j.format(height))
 	time.Sleep(200 * time.Millisecond)
 	return nil
 }
@@ -165,21 +174,3 @@ func (j *Jiecang) SaveMemory2() error {
 func (j *Jiecang) SaveMemory3() error {
 	return j.SaveMemory(3)
 }
-
-// readMemoryPreset decodes a memory preset height value from the controller response.
-// The function extracts the height from bytes 4-5 of the response buffer and converts
-// it from millimeters (protocol format) to centimeters (application format).
-//
-// Parameters:
-//   - buf: Response buffer from the controller. Expected format:
-//     [0xf2, 0xf2, type, 0x02, highByte, lowByte, checksum, 0x7e]
-//
-// Returns the preset height in centimeters, or 0 if the response type is invalid.
-func readMemoryPreset(buf []byte) uint8 {
-	if buf[3] == 0x02 {
-		preset := int(buf[4])*256 + int(buf[5])
-		// Hack to round the value
-		return uint8(math.Round(float64(preset / 10.0)))
-	}
-	return 0
-}
@@ -1,26 +1,132 @@
 package jiecang
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
 )
 
-func TestReadMemoryPreset(t *testing.T) {
+func TestReceiveMemoryPreset(t *testing.T) {
 	tests := []struct {
 		name           string // Name of the testcase
 		input          []byte // Input
-		expectedHeight uint8  // Expected result of function
+		expectedHeight Height // Expected result of function
 	}{
 		{
-			name:           "Correct height with incorrect checksum",
+			name:           "Valid message for memory preset",
 			input:          []byte{0xf2, 0xf2, 0x25, 0x02, 0x04, 0x4e, 0x79, 0x7e},
-			expectedHeight: 110,
+			expectedHeight: 1102,
+		},
+		{
+			name:           "Memory preset with incorrect checksum is ignored",
+			input:          []byte{0xf2, 0xf2, 0x25, 0x02, 0x04, 0x4e, 0x78, 0x7e},
+			expectedHeight: 0,
 		},
 	}
 
 	for _, test := range tests {
-		result := readMemoryPreset(test.input)
-		assert.Equal(t, test.expectedHeight, result, test.name)
+		ft := newFakeTransport()
+		ft.responses[protocol.CmdFetchSettings] = [][]byte{reply(0x25, 0x00, 0x00)}
+		j, err := New(ft)
+		require.NoError(t, err)
+		ft.notify(test.input)
+		assert.Equal(t, test.expectedHeight, j.presets["memory1"], test.name)
 	}
 }
+
+func TestGoToMemory(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	ft.notify(reply(0x26, 0x02, 0xda))
+	ft.notify(heightReply(1102))
+
+	// Controller moves to memory 2 once asked to
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == 0x06 {
+			f.notify(heightReply(730))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := j.GoToMemory(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, Arrived, res.Outcome)
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	assert.Equal(t, Height(730), j.currentHeight)
+	assert.Equal(t, 1, ft.count(commands["goto_memory2"].Encode()))
+}
+
+func TestGoToMemory4(t *testing.T) {
+	ft := newFakeTransport()
+	ft.responses[protocol.CmdFetchSettings] = append(ft.responses[protocol.CmdFetchSettings][:3], reply(0x28, 0x04, 0x1a))
+	j, err := New(ft)
+	require.NoError(t, err)
+	ft.notify(heightReply(730))
+	assert.Equal(t, 4, j.MemoryPresets())
+
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == protocol.CmdGoToMemory4 {
+			f.notify(heightReply(1050))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = j.GoToMemory(ctx, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ft.count([]byte{0xf1, 0xf1, 0x28, 0x00, 0x28, 0x7e}))
+
+	require.NoError(t, j.SaveMemory(4))
+	assert.Equal(t, 1, ft.count([]byte{0xf1, 0xf1, 0x26, 0x00, 0x26, 0x7e}))
+}
+
+func TestMemoryPresetsWithoutMemory4(t *testing.T) {
+	ft := newFakeTransport()
+	// No 0x28 frame at all
+	ft.responses[protocol.CmdFetchSettings] = ft.responses[protocol.CmdFetchSettings][:3]
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, j.MemoryPresets())
+	_, err = j.GoToMemory(context.Background(), 4)
+	assert.Error(t, err)
+	assert.Error(t, j.SaveMemory(4))
+}
+
+func TestSaveMemory4NotSet(t *testing.T) {
+	ft := newFakeTransport()
+	ft.responses[protocol.CmdFetchSettings][3] = reply(0x28, 0x00, 0x00)
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, j.MemoryPresets())
+	require.NoError(t, j.SaveMemory(4))
+	assert.Equal(t, 1, ft.count(commands["save_memory4"].Encode()))
+}
+
+func TestGoToMemoryNotSet(t *testing.T) {
+	j, err := New(newFakeTransport())
+	require.NoError(t, err)
+
+	// Memory preset 3 is reported as 0
+	_, err = j.GoToMemory(context.Background(), 3)
+	assert.Error(t, err)
+}
+
+func TestGoToMemoryInvalid(t *testing.T) {
+	j, err := New(newFakeTransport())
+	require.NoError(t, err)
+
+	_, err = j.GoToMemory(context.Background(), 0)
+	assert.Error(t, err)
+	_, err = j.GoToMemory(context.Background(), 5)
+	assert.Error(t, err)
+}
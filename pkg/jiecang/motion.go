@@ -0,0 +1,310 @@
+package jiecang
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// This file contains the motion controller used to move the desk to a
+// target height and tell how the movement ended.
+
+const (
+	// defaultArrivalTolerance is how far from the target height the desk
+	// can stop and still be considered arrived.
+	defaultArrivalTolerance = 2 * Millimeter
+
+	// motionPollInterval is how often movement commands are repeated,
+	// emulating a held button.
+	motionPollInterval = 200 * time.Millisecond
+
+	// defaultStallTimeout is how long the height may stay the same during a
+	// movement before the desk is considered stalled.
+	defaultStallTimeout = 2 * time.Second
+
+	// reversalDistance is how far the desk may move away from the target,
+	// against the direction of travel, before a collision is suspected.
+	// Controllers back off after the anti-collision sensor trips.
+	reversalDistance = 5 * Millimeter
+
+	// maxOvershoots is how many times the desk may pass the target before
+	// giving up on reaching it.
+	maxOvershoots = 2
+
+	// settleTimeout bounds how long the desk is given to settle once a
+	// movement is over.
+	settleTimeout = time.Second
+)
+
+// Outcome is how a movement ended.
+type Outcome int
+
+const (
+	// Arrived means that the desk stopped within the tolerance of the target.
+	Arrived Outcome = iota
+	// Stalled means that the desk stopped moving before reaching the target,
+	// or kept passing it.
+	Stalled
+	// CollisionSuspected means that the desk reversed its direction of
+	// travel, like controllers do when the anti-collision sensor trips.
+	CollisionSuspected
+	// Cancelled means that the context was done before the desk arrived.
+	Cancelled
+)
+
+// String returns the name of o.
+func (o Outcome) String() string {
+	switch o {
+	case Arrived:
+		return "arrived"
+	case Stalled:
+		return "stalled"
+	case CollisionSuspected:
+		return "collision suspected"
+	case Cancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("Outcome(%d)", int(o))
+}
+
+// MoveResult describes a finished movement.
+type MoveResult struct {
+	Outcome  Outcome
+	Height   Height        // Height when the movement ended
+	Target   Height        // Requested height
+	Duration time.Duration // Time from the first command to the end
+	Speed    float64       // Average speed while moving, in mm/s
+}
+
+// motionTracker follows the height reports of a movement towards a target
+// and decides when the movement is over.
+type motionTracker struct {
+	target       Height
+	tolerance    Height
+	stallTimeout time.Duration
+
+	start      Height    // Height when the movement started
+	travel     int       // Direction of travel: +1 up, -1 down
+	extreme    Height    // Furthest height reached in the direction of travel
+	last       Height    // Last reported height
+	lastChange time.Time // When the height last changed
+	travelled  Height    // Distance covered since the start
+	begin      time.Time // When the movement started
+	overshoots int       // How many times the target was passed
+}
+
+// newMotionTracker starts tracking a movement from start to target.
+func newMotionTracker(start, target, tolerance Height, stallTimeout time.Duration, now time.Time) *motionTracker {
+	m := &motionTracker{
+		target:       target,
+		tolerance:    tolerance,
+		stallTimeout: stallTimeout,
+		start:        start,
+		travel:       1,
+		extreme:      start,
+		last:         start,
+		lastChange:   now,
+		begin:        now,
+	}
+	if target < start {
+		m.travel = -1
+	}
+	return m
+}
+
+// arrived reports whether h is within the tolerance of the target.
+func (m *motionTracker) arrived(h Height) bool {
+	return h.distance(m.target) <= m.tolerance
+}
+
+// reached reports whether h is within the tolerance of the target or past
+// it, in the direction of travel.
+func (m *motionTracker) reached(h Height) bool {
+	return m.travel*int(h) >= m.travel*int(m.target)-int(m.tolerance)
+}
+
+// record records a height report, without deciding anything.
+func (m *motionTracker) record(h Height, now time.Time) {
+	m.travelled += h.distance(m.last)
+	m.last = h
+	m.lastChange = now
+}
+
+// update records a height report and returns the outcome of the movement,
+// if it is over.
+func (m *motionTracker) update(h Height, now time.Time) (Outcome, bool) {
+	if h == m.last {
+		return 0, false
+	}
+	m.record(h, now)
+
+	if m.arrived(h) {
+		return Arrived, true
+	}
+
+	// offset is the position along the direction of travel, so that the
+	// same checks apply to both directions.
+	offset := func(h Height) int { return m.travel * int(h) }
+	if offset(h) > offset(m.extreme) {
+		m.extreme = h
+	}
+	if offset(m.extreme)-offset(h) > int(reversalDistance) {
+		return CollisionSuspected, true
+	}
+	if offset(h) > offset(m.target)+int(m.tolerance) {
+		// Passed the target, the controller is expected to come back
+		m.overshoots++
+		if m.overshoots > maxOvershoots {
+			return Stalled, true
+		}
+		m.travel = -m.travel
+		m.extreme = h
+	}
+	return 0, false
+}
+
+// speed returns the average speed of the desk in mm/s, from the start of
+// the movement to the last height change.
+func (m *motionTracker) speed() float64 {
+	dt := m.lastChange.Sub(m.begin).Seconds()
+	if dt <= 0 {
+		return 0
+	}
+	return float64(m.travelled) / dt
+}
+
+// stalled reports whether the height has not changed for too long.
+func (m *motionTracker) stalled(now time.Time) bool {
+	return now.Sub(m.lastChange) >= m.stallTimeout
+}
+
+// moveOptions changes how move drives the desk.
+type moveOptions struct {
+	// deadline, if not nil, ends the movement as arrived when it fires.
+	deadline <-chan time.Time
+	// hold stops the desk as soon as it reaches or passes the target, like
+	// releasing a held button, rather than leaving it to the controller.
+	hold bool
+}
+
+// move repeats command until the desk reaches target, stalls, reverses or
+// ctx is done. The desk is stopped unless it arrived, or always if
+// opts.hold is set.
+//
+// Once arrived, or stopped while holding, the desk is given time to settle
+// (its height not changing for motionPollInterval), so that the reported
+// height is the final one.
+func (j *Jiecang) move(ctx context.Context, command protocol.Frame, target Height, opts moveOptions) (MoveResult, error) {
+	// Subscribe before reading the height, so that no report is missed
+	subCtx, unsubscribe := context.WithCancel(context.Background())
+	defer unsubscribe()
+	events := j.events.subscribe(subCtx, false)
+
+	j.mu.RLock()
+	start, tolerance := j.currentHeight, j.ArrivalTolerance
+	j.mu.RUnlock()
+
+	m := newMotionTracker(start, target, tolerance, j.stallTimeout, time.Now())
+	result := func(o Outcome) MoveResult {
+		return MoveResult{Outcome: o, Height: m.last, Target: target, Duration: time.Since(m.begin), Speed: m.speed()}
+	}
+
+	j.reportProgress(Progress{Start: start, Height: start, Target: target})
+	outcome := Arrived
+	switch {
+	case opts.hold && m.reached(start):
+		// A desk already at the target is stopped anyway, in case it moves
+	case m.arrived(start):
+		return result(Arrived), nil
+	default:
+		var err error
+		if outcome, err = j.drive(ctx, command, m, events, opts); err != nil {
+			return result(outcome), err
+		}
+	}
+
+	if opts.hold || outcome != Arrived {
+		if err := j.sendCommand(commands["stop"]); err != nil {
+			return result(outcome), fmt.Errorf("failed to send stop command: %w", err)
+		}
+	}
+	if opts.hold || outcome == Arrived {
+		j.settle(m, events)
+	}
+	return result(outcome), nil
+}
+
+// drive repeats command until the movement tracked by m is over and
+// returns its outcome. The desk is not stopped.
+func (j *Jiecang) drive(ctx context.Context, command protocol.Frame, m *motionTracker, events <-chan Event, opts moveOptions) (Outcome, error) {
+	if err := j.sendCommand(command); err != nil {
+		return Stalled, fmt.Errorf("failed to send move command: %w", err)
+	}
+
+	ticker := time.NewTicker(motionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return Cancelled, fmt.Errorf("desk disconnected")
+			}
+			h, isHeight := e.(HeightChanged)
+			if !isHeight {
+				continue
+			}
+			j.reportProgress(Progress{Start: m.start, Height: h.Height, Target: m.target})
+			// When holding, passing the target between two reports counts
+			// as reaching it
+			reached := opts.hold && m.reached(h.Height)
+			outcome, over := m.update(h.Height, time.Now())
+			if reached {
+				return Arrived, nil
+			}
+			if over {
+				return outcome, nil
+			}
+		case <-opts.deadline:
+			return Arrived, nil
+		case <-ctx.Done():
+			return Cancelled, nil
+		case now := <-ticker.C:
+			if m.stalled(now) {
+				return Stalled, nil
+			}
+			if err := j.sendCommand(command); err != nil {
+				return Stalled, fmt.Errorf("failed to send move command: %w", err)
+			}
+		}
+	}
+}
+
+// settle follows the height reports once the movement is over, until the
+// height stops changing.
+func (j *Jiecang) settle(m *motionTracker, events <-chan Event) {
+	timeout := time.NewTimer(settleTimeout)
+	defer timeout.Stop()
+	idle := time.NewTimer(motionPollInterval)
+	defer idle.Stop()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if h, isHeight := e.(HeightChanged); isHeight {
+				m.record(h.Height, time.Now())
+				j.reportProgress(Progress{Start: m.start, Height: h.Height, Target: m.target})
+				idle.Reset(motionPollInterval)
+			}
+		case <-idle.C:
+			return
+		case <-timeout.C:
+			return
+		}
+	}
+}
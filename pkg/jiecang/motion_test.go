@@ -0,0 +1,162 @@
+package jiecang
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestMotionTracker(t *testing.T) {
+	tests := []struct {
+		name            string  // Name of the testcase
+		start           Height  // Height when the movement starts
+		target          Height  // Target height
+		reports         []int   // Height reports, in order
+		expectedOutcome Outcome // Expected outcome
+		expectedOver    bool    // Whether the movement is expected to be over
+	}{
+		{
+			name:            "Arrives going up",
+			start:           800,
+			target:          850,
+			reports:         []int{810, 830, 849},
+			expectedOutcome: Arrived,
+			expectedOver:    true,
+		},
+		{
+			name:            "Arrives going down",
+			start:           850,
+			target:          800,
+			reports:         []int{840, 820, 802},
+			expectedOutcome: Arrived,
+			expectedOver:    true,
+		},
+		{
+			name:    "Still moving",
+			start:   800,
+			target:  850,
+			reports: []int{810, 820},
+		},
+		{
+			name:            "Reverses after hitting an obstacle",
+			start:           800,
+			target:          850,
+			reports:         []int{810, 820, 814},
+			expectedOutcome: CollisionSuspected,
+			expectedOver:    true,
+		},
+		{
+			name:    "Small jitter is not a reversal",
+			start:   800,
+			target:  850,
+			reports: []int{810, 820, 817},
+		},
+		{
+			name:            "Overshoots and comes back",
+			start:           800,
+			target:          850,
+			reports:         []int{840, 856, 851},
+			expectedOutcome: Arrived,
+			expectedOver:    true,
+		},
+		{
+			name:            "Keeps passing the target",
+			start:           800,
+			target:          850,
+			reports:         []int{856, 844, 856, 844},
+			expectedOutcome: Stalled,
+			expectedOver:    true,
+		},
+	}
+
+	for _, test := range tests {
+		now := time.Now()
+		m := newMotionTracker(test.start, test.target, 2*Millimeter, time.Second, now)
+		var outcome Outcome
+		var over bool
+		for _, h := range test.reports {
+			now = now.Add(100 * time.Millisecond)
+			outcome, over = m.update(Height(h), now)
+			if over {
+				break
+			}
+		}
+		assert.Equal(t, test.expectedOver, over, test.name)
+		if test.expectedOver {
+			assert.Equal(t, test.expectedOutcome, outcome, test.name)
+		}
+	}
+}
+
+func TestMotionTrackerStalled(t *testing.T) {
+	now := time.Now()
+	m := newMotionTracker(800, 850, 2*Millimeter, time.Second, now)
+	m.update(820, now.Add(500*time.Millisecond))
+
+	assert.False(t, m.stalled(now.Add(time.Second)))
+	assert.True(t, m.stalled(now.Add(1500*time.Millisecond)))
+	assert.InDelta(t, 40, m.speed(), 0.01)
+}
+
+func TestGoToHeightStalled(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	j.stallTimeout = 300 * time.Millisecond
+	ft.notify(heightReply(800))
+
+	// Controller moves a bit, then refuses to move any further
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == protocol.CmdGoToHeight {
+			f.notify(heightReply(810))
+		}
+	}
+
+	res, err := j.GoToHeight(context.Background(), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, Stalled, res.Outcome)
+	assert.Equal(t, Height(810), res.Height)
+	assert.Equal(t, Height(1000), res.Target)
+	assert.Equal(t, 1, ft.count(commands["stop"].Encode()))
+}
+
+func TestGoToHeightCollision(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	ft.notify(heightReply(800))
+
+	// Controller backs off after hitting an obstacle
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == protocol.CmdGoToHeight {
+			for _, h := range []Height{810, 820, 830, 820} {
+				f.notify(heightReply(h))
+			}
+		}
+	}
+
+	res, err := j.GoToHeight(context.Background(), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, CollisionSuspected, res.Outcome)
+	assert.Equal(t, Height(820), res.Height)
+	assert.Equal(t, 1, ft.count(commands["stop"].Encode()))
+}
+
+func TestGoToHeightCancelled(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	ft.notify(heightReply(800))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	res, err := j.GoToHeight(ctx, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, Cancelled, res.Outcome)
+	assert.Equal(t, Height(800), res.Height)
+	assert.Equal(t, 1, ft.count(commands["stop"].Encode()))
+}
@@ -0,0 +1,71 @@
+package jiecang
+
+import (
+	"context"
+	"log/slog"
+)
+
+// This file contains the options accepted by New and Init. The package does
+// not write to stdout or to the standard logger: progress of movements is
+// reported through a ProgressFunc and diagnostics through a slog.Logger.
+
+// Option configures a Jiecang controller, see New.
+type Option func(*Jiecang)
+
+// Progress describes an ongoing movement towards a target height.
+type Progress struct {
+	Start  Height // Height when the movement started
+	Height Height // Current height
+	Target Height // Target height
+}
+
+// Fraction returns how much of the distance from Start to Target is
+// covered, between 0 and 1.
+func (p Progress) Fraction() float64 {
+	total := p.Start.distance(p.Target)
+	if total == 0 {
+		return 1
+	}
+	remaining := p.Height.distance(p.Target)
+	if remaining >= total {
+		return 0
+	}
+	return 1 - float64(remaining)/float64(total)
+}
+
+// ProgressFunc receives the progress of GoToHeight and GoToMemory: once when
+// the movement starts and every time the controller reports a new height.
+// It is called from the goroutine moving the desk and must not block.
+type ProgressFunc func(Progress)
+
+// WithProgress sets the function receiving the progress of movements.
+func WithProgress(f ProgressFunc) Option {
+	return func(j *Jiecang) {
+		j.progress = f
+	}
+}
+
+// WithLogger sets the logger used for diagnostics, such as unexpected frames
+// and reconnections. By default nothing is logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(j *Jiecang) {
+		if l != nil {
+			j.logger = l
+		}
+	}
+}
+
+// discardHandler is a slog.Handler dropping all records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// reportProgress passes p to the ProgressFunc, if any.
+func (j *Jiecang) reportProgress(p Progress) {
+	if j.progress != nil {
+		j.progress(p)
+	}
+}
@@ -0,0 +1,98 @@
+package jiecang
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestProgressFraction(t *testing.T) {
+	tests := []struct {
+		name             string   // Name of the testcase
+		progress         Progress // Input
+		expectedFraction float64  // Expected result of function
+	}{
+		{
+			name:             "Start of movement",
+			progress:         Progress{Start: 800, Height: 800, Target: 1000},
+			expectedFraction: 0,
+		},
+		{
+			name:             "Halfway going up",
+			progress:         Progress{Start: 800, Height: 900, Target: 1000},
+			expectedFraction: 0.5,
+		},
+		{
+			name:             "Halfway going down",
+			progress:         Progress{Start: 1000, Height: 900, Target: 800},
+			expectedFraction: 0.5,
+		},
+		{
+			name:             "Moving away from target",
+			progress:         Progress{Start: 800, Height: 790, Target: 1000},
+			expectedFraction: 0,
+		},
+		{
+			name:             "Already at target",
+			progress:         Progress{Start: 800, Height: 800, Target: 800},
+			expectedFraction: 1,
+		},
+	}
+
+	for _, test := range tests {
+		assert.InDelta(t, test.expectedFraction, test.progress.Fraction(), 0.001, test.name)
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	var mu sync.Mutex
+	var reports []Progress
+	ft := newFakeTransport()
+	j, err := New(ft, WithProgress(func(p Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, p)
+	}))
+	require.NoError(t, err)
+	ft.notify(heightReply(800))
+
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == protocol.CmdGoToHeight {
+			f.notify(heightReply(900))
+			f.notify(heightReply(1000))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := j.GoToHeight(ctx, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, Arrived, res.Outcome)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []Progress{
+		{Start: 800, Height: 800, Target: 1000},
+		{Start: 800, Height: 900, Target: 1000},
+		{Start: 800, Height: 1000, Target: 1000},
+	}, reports)
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ft := newFakeTransport()
+	_, err := New(ft, WithLogger(logger))
+	require.NoError(t, err)
+
+	ft.notify(reply(0x55, 0x01))
+	assert.Contains(t, buf.String(), "unknown frame")
+	assert.Contains(t, buf.String(), "f2f2550101577e")
+}
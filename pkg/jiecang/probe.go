@@ -0,0 +1,130 @@
+package jiecang
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// This file contains the capability probe. Controllers differ in the
+// features they support; Probe finds out what the connected controller
+// answers and WithProfile makes the package rely on it instead of assuming
+// every feature is available.
+
+// ErrUnsupported is returned when using a feature that the controller does
+// not support according to its profile, see WithProfile.
+var ErrUnsupported = errors.New("not supported by the controller")
+
+// probeQueries are the read-only queries sent by Probe. None of them moves
+// the desk or changes a setting.
+var probeQueries = []string{
+	"fetch_height",
+	"fetch_height_range",
+	"query_limits",
+	"fetch_stand_time",
+	"fetch_all_time",
+}
+
+// Profile describes the capabilities of a controller, as found by Probe.
+type Profile struct {
+	MemoryPresets int  `json:"memory_presets"` // Number of memory presets
+	Units         bool `json:"units"`          // Reports and changes its units setting
+	MemoryMode    bool `json:"memory_mode"`    // Reports and changes its memory mode
+	AntiCollision bool `json:"anti_collision"` // Reports and changes its anti-collision sensitivity
+	Limits        bool `json:"limits"`         // Supports user-defined height limits
+	Stats         bool `json:"stats"`          // Reports usage statistics
+
+	// Unknown17 holds the distinct payloads of the notification 0x17,
+	// whose meaning is unknown, in hex.
+	Unknown17 []string `json:"unknown_17,omitempty"`
+
+	// Queries holds the queries sent by the probe and their replies.
+	Queries []ProbeQuery `json:"queries"`
+}
+
+// ProbeQuery is a query sent by Probe and the frames received in reply.
+type ProbeQuery struct {
+	Name    string   `json:"name"`
+	Command string   `json:"command"` // Command frame, in hex
+	Replies []string `json:"replies"` // Frames received, in hex
+}
+
+// Probe sends a curated list of read-only queries to the controller and
+// returns the profile of its capabilities, based on the replies received
+// within the query timeout of each query. The desk state is updated from
+// the replies like for any other query.
+//
+// Returns an error if the command transmission fails or ctx is cancelled.
+//
+// Example:
+//
+//	profile, err := desk.Probe(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("Limits supported: %t\n", profile.Limits)
+func (j *Jiecang) Probe(ctx context.Context) (Profile, error) {
+	p := Profile{MemoryPresets: defaultMemorySlots}
+	for _, name := range probeQueries {
+		command := commands[name]
+		replies, err := j.SendRaw(ctx, command, j.queryTimeout)
+		if err != nil {
+			return Profile{}, fmt.Errorf("query %s: %w", name, err)
+		}
+
+		q := ProbeQuery{Name: name, Command: hex.EncodeToString(command.Encode()), Replies: []string{}}
+		for _, r := range replies {
+			q.Replies = append(q.Replies, hex.EncodeToString(r.Raw))
+			p.add(r.Message)
+		}
+		p.Queries = append(p.Queries, q)
+	}
+	return p, nil
+}
+
+// add records the capability shown by a reply of the controller.
+func (p *Profile) add(msg protocol.Message) {
+	switch m := msg.(type) {
+	case protocol.MemoryPreset:
+		p.MemoryPresets = max(p.MemoryPresets, m.Slot)
+	case protocol.Units:
+		p.Units = true
+	case protocol.MemoryMode:
+		p.MemoryMode = true
+	case protocol.AntiCollision:
+		p.AntiCollision = true
+	case protocol.Limits, protocol.MaxLimit, protocol.MinLimit:
+		p.Limits = true
+	case protocol.StandTime, protocol.AllTime:
+		p.Stats = true
+	case protocol.Unknown:
+		payload := hex.EncodeToString(m.Frame.Payload)
+		if m.Frame.Type == protocol.MsgUnknown17 && !slices.Contains(p.Unknown17, payload) {
+			p.Unknown17 = append(p.Unknown17, payload)
+		}
+	}
+}
+
+// WithProfile makes the controller rely on p, usually found by Probe on an
+// earlier connection: features that p does not support fail with
+// ErrUnsupported and are not queried during initialization, and the number
+// of memory presets is taken from p. Without a profile, every feature is
+// assumed to be supported.
+func WithProfile(p Profile) Option {
+	return func(j *Jiecang) {
+		j.profile = &p
+	}
+}
+
+// supports returns an error wrapping ErrUnsupported if feature is not
+// supported according to the profile of the controller.
+func (j *Jiecang) supports(feature string, supported func(Profile) bool) error {
+	if j.profile == nil || supported(*j.profile) {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", feature, ErrUnsupported)
+}
@@ -0,0 +1,96 @@
+package jiecang
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name            string   // Name of the testcase
+		unanswered      []byte   // Queries the controller does not answer
+		settings        [][]byte // Extra replies to the settings query
+		expectedProfile Profile  // Expected result of function, without queries
+	}{
+		{
+			name: "Controller supporting everything",
+			settings: [][]byte{
+				reply(0x0e, 0x00),
+				reply(0x19, 0x00),
+				reply(0x1d, 0x02),
+				reply(0x17, 0x01),
+				reply(0x17, 0x01),
+			},
+			expectedProfile: Profile{
+				MemoryPresets: 4,
+				Units:         true,
+				MemoryMode:    true,
+				AntiCollision: true,
+				Limits:        true,
+				Stats:         true,
+				Unknown17:     []string{"01"},
+			},
+		},
+		{
+			name:            "Controller without limits and statistics",
+			unanswered:      []byte{protocol.CmdQueryLimits, protocol.CmdFetchStandTime, protocol.CmdFetchAllTime},
+			expectedProfile: Profile{MemoryPresets: 4},
+		},
+	}
+
+	for _, test := range tests {
+		ft := newFakeTransport()
+		j, err := New(ft)
+		require.NoError(t, err, test.name)
+		j.queryTimeout = 20 * time.Millisecond
+
+		for _, cmd := range test.unanswered {
+			delete(ft.responses, cmd)
+		}
+		ft.responses[protocol.CmdFetchSettings] = append(ft.responses[protocol.CmdFetchSettings], test.settings...)
+
+		p, err := j.Probe(context.Background())
+		require.NoError(t, err, test.name)
+		require.Len(t, p.Queries, len(probeQueries), test.name)
+		assert.Equal(t, "fetch_height", p.Queries[0].Name, test.name)
+		assert.Equal(t, "f1f10700077e", p.Queries[0].Command, test.name)
+		assert.Contains(t, p.Queries[0].Replies, "f2f22502044e797e", test.name)
+
+		p.Queries = nil
+		assert.Equal(t, test.expectedProfile, p, test.name)
+	}
+}
+
+func TestWithProfile(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft, WithProfile(Profile{MemoryPresets: 3}))
+	require.NoError(t, err)
+
+	// Unsupported features are not queried during initialization
+	assert.Equal(t, 0, ft.count(commands["query_limits"].Encode()))
+	assert.Equal(t, 0, ft.count(commands["fetch_stand_time"].Encode()))
+
+	// Memory preset 4 is reported, but the profile takes precedence
+	assert.Equal(t, 3, j.MemoryPresets())
+	_, err = j.GoToMemory(context.Background(), 4)
+	assert.Error(t, err)
+
+	require.NoError(t, j.FetchHeightRange())
+	assert.Equal(t, 0, ft.count(commands["query_limits"].Encode()))
+
+	ctx := context.Background()
+	_, err = j.QueryLimits(ctx)
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.ErrorIs(t, j.SetLimits(ctx, 700, 1000), ErrUnsupported)
+	assert.Equal(t, 0, ft.count(protocol.GoToHeight(700).Encode()), "The desk does not move")
+	_, err = j.QueryUsageStats(ctx)
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.ErrorIs(t, j.SetUnits(ctx, UnitInches), ErrUnsupported)
+	assert.ErrorIs(t, j.SetMemoryMode(ctx, MemoryModeConstantTouch), ErrUnsupported)
+	assert.ErrorIs(t, j.SetAntiCollisionSensitivity(ctx, AntiCollisionLow), ErrUnsupported)
+}
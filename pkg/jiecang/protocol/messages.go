@@ -0,0 +1,203 @@
+package protocol
+
+import "fmt"
+
+// Message is a decoded notification from the controller. It is one of
+// HeightReport, HeightRange, MemoryPreset, Units, MemoryMode, AntiCollision,
+// Limits, MaxLimit, MinLimit, StandTime, AllTime or Unknown.
+type Message interface {
+	isMessage()
+}
+
+// HeightReport carries the current height of the desk.
+type HeightReport struct {
+	Height uint16 // Height in millimeters
+}
+
+// HeightRange carries the physical height range of the desk.
+type HeightRange struct {
+	Highest uint16 // Maximum height in millimeters
+	Lowest  uint16 // Minimum height in millimeters
+}
+
+// MemoryPreset carries the height saved in a memory preset.
+type MemoryPreset struct {
+	Slot   int    // Memory preset number (1-4)
+	Height uint16 // Height in millimeters, 0 if the preset is not set
+}
+
+// Unit is a display unit setting of the controller.
+type Unit byte
+
+const (
+	UnitCentimeters Unit = 0x00
+	UnitInches      Unit = 0x01
+)
+
+// Units carries the units setting of the controller.
+type Units struct {
+	Unit Unit
+}
+
+// MemoryMode carries the memory mode setting of the controller.
+type MemoryMode struct {
+	// ConstantTouch is true if the memory buttons need to be held
+	// (constant touch mode) and false for one-touch mode.
+	ConstantTouch bool
+}
+
+// AntiCollision carries the anti-collision sensitivity setting.
+type AntiCollision struct {
+	Sensitivity uint8 // 1 = High, 2 = Medium, 3 = Low
+}
+
+// Limits carries which user-defined height limits are set. The heights of
+// the limits that are set follow as MaxLimit and MinLimit.
+type Limits struct {
+	MaxSet bool
+	MinSet bool
+}
+
+// Flags of the Limits payload.
+const (
+	LimitMaxSet = 0x01
+	LimitMinSet = 0x10
+)
+
+// MaxLimit carries the user-defined upper height limit.
+type MaxLimit struct {
+	Height uint16 // Height in millimeters
+}
+
+// MinLimit carries the user-defined lower height limit.
+type MinLimit struct {
+	Height uint16 // Height in millimeters
+}
+
+// StandTime carries the standing time counter of the controller.
+//
+// The payload is decoded as a big-endian count of minutes. This is
+// reverse-engineered and has not been confirmed on every controller, the
+// raw frame is kept in Frame.
+type StandTime struct {
+	Minutes uint32
+	Frame   Frame
+}
+
+// AllTime carries the total usage time counter of the controller, decoded
+// like StandTime.
+type AllTime struct {
+	Minutes uint32
+	Frame   Frame
+}
+
+// Unknown carries any notification without a known meaning.
+type Unknown struct {
+	Frame Frame
+}
+
+func (HeightReport) isMessage()  {}
+func (HeightRange) isMessage()   {}
+func (MemoryPreset) isMessage()  {}
+func (Units) isMessage()         {}
+func (MemoryMode) isMessage()    {}
+func (AntiCollision) isMessage() {}
+func (Limits) isMessage()        {}
+func (MaxLimit) isMessage()      {}
+func (MinLimit) isMessage()      {}
+func (StandTime) isMessage()     {}
+func (AllTime) isMessage()       {}
+func (Unknown) isMessage()       {}
+
+// Decode validates a notification frame received from the controller and
+// decodes it into a typed Message.
+//
+// Returns an error wrapping ErrInvalidFrame if the frame is not valid, or an
+// error if the payload of a known message type is malformed. Valid frames of
+// unknown type are returned as Unknown.
+func Decode(buf []byte) (Message, error) {
+	f, err := ParseNotification(buf)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeFrame(f)
+}
+
+// DecodeFrame decodes an already parsed notification frame into a typed
+// Message. See Decode.
+func DecodeFrame(f Frame) (Message, error) {
+	p := f.Payload
+	switch f.Type {
+	case MsgHeight:
+		// f2 f2 01 03 03 37 07 45 7e
+		// Meaning of the third byte is unknown
+		if len(p) < 2 {
+			return nil, payloadError(f, 2)
+		}
+		return HeightReport{Height: uint16(p[0])<<8 | uint16(p[1])}, nil
+	case MsgHeightRange:
+		if len(p) != 4 {
+			return nil, payloadError(f, 4)
+		}
+		return HeightRange{
+			Highest: uint16(p[0])<<8 | uint16(p[1]),
+			Lowest:  uint16(p[2])<<8 | uint16(p[3]),
+		}, nil
+	case MsgMemoryPreset1, MsgMemoryPreset2, MsgMemoryPreset3, MsgMemoryPreset4:
+		if len(p) != 2 {
+			return nil, payloadError(f, 2)
+		}
+		return MemoryPreset{
+			Slot:   int(f.Type-MsgMemoryPreset1) + 1,
+			Height: uint16(p[0])<<8 | uint16(p[1]),
+		}, nil
+	case MsgUnits:
+		if len(p) != 1 {
+			return nil, payloadError(f, 1)
+		}
+		return Units{Unit: Unit(p[0])}, nil
+	case MsgMemoryMode:
+		if len(p) != 1 {
+			return nil, payloadError(f, 1)
+		}
+		return MemoryMode{ConstantTouch: p[0] == 0x01}, nil
+	case MsgAntiCollision:
+		if len(p) != 1 {
+			return nil, payloadError(f, 1)
+		}
+		return AntiCollision{Sensitivity: p[0]}, nil
+	case MsgLimits:
+		if len(p) != 1 {
+			return nil, payloadError(f, 1)
+		}
+		return Limits{MaxSet: p[0]&LimitMaxSet != 0, MinSet: p[0]&LimitMinSet != 0}, nil
+	case MsgMaxLimit:
+		if len(p) != 2 {
+			return nil, payloadError(f, 2)
+		}
+		return MaxLimit{Height: uint16(p[0])<<8 | uint16(p[1])}, nil
+	case MsgMinLimit:
+		if len(p) != 2 {
+			return nil, payloadError(f, 2)
+		}
+		return MinLimit{Height: uint16(p[0])<<8 | uint16(p[1])}, nil
+	case MsgStandTime, MsgAllTime:
+		if len(p) < 1 || len(p) > 4 {
+			return nil, fmt.Errorf("message %02x: expected 1-4 bytes of payload, got %d", f.Type, len(p))
+		}
+		var minutes uint32
+		for _, b := range p {
+			minutes = minutes<<8 | uint32(b)
+		}
+		if f.Type == MsgStandTime {
+			return StandTime{Minutes: minutes, Frame: f}, nil
+		}
+		return AllTime{Minutes: minutes, Frame: f}, nil
+	default:
+		return Unknown{Frame: f}, nil
+	}
+}
+
+func payloadError(f Frame, expected int) error {
+	return fmt.Errorf("message %02x: expected %d bytes of payload, got %d", f.Type, expected, len(f.Payload))
+}
@@ -0,0 +1,117 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name            string  // Name of the testcase
+		input           []byte  // Input
+		expectedMessage Message // Expected result of function
+		expectedError   bool    // Whether decoding should fail
+	}{
+		{
+			name:            "Valid message for height",
+			input:           []byte{0xf2, 0xf2, 0x01, 0x03, 0x03, 0x37, 0x07, 0x45, 0x7e},
+			expectedMessage: HeightReport{Height: 823},
+		},
+		{
+			name:          "Height with incorrect checksum",
+			input:         []byte{0xf2, 0xf2, 0x01, 0x03, 0x03, 0x37, 0x07, 0x46, 0x7e},
+			expectedError: true,
+		},
+		{
+			name:            "Valid message for height range",
+			input:           []byte{0xf2, 0xf2, 0x07, 0x04, 0x04, 0xf8, 0x02, 0x6c, 0x75, 0x7e},
+			expectedMessage: HeightRange{Highest: 1272, Lowest: 620},
+		},
+		{
+			name:            "Valid message for memory presets",
+			input:           []byte{0xf2, 0xf2, 0x25, 0x02, 0x04, 0x4e, 0x79, 0x7e},
+			expectedMessage: MemoryPreset{Slot: 1, Height: 1102},
+		},
+		{
+			name:            "Memory preset 4",
+			input:           Frame{Type: MsgMemoryPreset4, Payload: []byte{0x00, 0x00}}.EncodeNotification(),
+			expectedMessage: MemoryPreset{Slot: 4},
+		},
+		{
+			name:            "Valid message for unit settings",
+			input:           []byte{0xf2, 0xf2, 0x0e, 0x01, 0x00, 0x0f, 0x7e},
+			expectedMessage: Units{Unit: UnitCentimeters},
+		},
+		{
+			name:            "Constant touch memory mode",
+			input:           Frame{Type: MsgMemoryMode, Payload: []byte{0x01}}.EncodeNotification(),
+			expectedMessage: MemoryMode{ConstantTouch: true},
+		},
+		{
+			name:            "One-touch memory mode",
+			input:           Frame{Type: MsgMemoryMode, Payload: []byte{0x00}}.EncodeNotification(),
+			expectedMessage: MemoryMode{ConstantTouch: false},
+		},
+		{
+			name:            "Anti-collision sensitivity",
+			input:           Frame{Type: MsgAntiCollision, Payload: []byte{0x03}}.EncodeNotification(),
+			expectedMessage: AntiCollision{Sensitivity: 3},
+		},
+		{
+			name:            "Both user limits set",
+			input:           Frame{Type: MsgLimits, Payload: []byte{0x11}}.EncodeNotification(),
+			expectedMessage: Limits{MaxSet: true, MinSet: true},
+		},
+		{
+			name:            "Upper user limit set",
+			input:           Frame{Type: MsgLimits, Payload: []byte{0x01}}.EncodeNotification(),
+			expectedMessage: Limits{MaxSet: true},
+		},
+		{
+			name:            "Upper user limit",
+			input:           Frame{Type: MsgMaxLimit, Payload: []byte{0x04, 0x7e}}.EncodeNotification(),
+			expectedMessage: MaxLimit{Height: 1150},
+		},
+		{
+			name:            "Lower user limit",
+			input:           Frame{Type: MsgMinLimit, Payload: []byte{0x02, 0xbc}}.EncodeNotification(),
+			expectedMessage: MinLimit{Height: 700},
+		},
+		{
+			name:            "Stand time",
+			input:           Frame{Type: MsgStandTime, Payload: []byte{0x00, 0x00, 0x12, 0x34}}.EncodeNotification(),
+			expectedMessage: StandTime{Minutes: 0x1234, Frame: Frame{Type: MsgStandTime, Payload: []byte{0x00, 0x00, 0x12, 0x34}}},
+		},
+		{
+			name:            "All time with short counter",
+			input:           Frame{Type: MsgAllTime, Payload: []byte{0x01, 0x00}}.EncodeNotification(),
+			expectedMessage: AllTime{Minutes: 256, Frame: Frame{Type: MsgAllTime, Payload: []byte{0x01, 0x00}}},
+		},
+		{
+			name:          "Stand time without payload",
+			input:         Frame{Type: MsgStandTime}.EncodeNotification(),
+			expectedError: true,
+		},
+		{
+			name:            "Unknown message",
+			input:           Frame{Type: MsgUnknown17, Payload: []byte{0x01, 0x02}}.EncodeNotification(),
+			expectedMessage: Unknown{Frame: Frame{Type: MsgUnknown17, Payload: []byte{0x01, 0x02}}},
+		},
+		{
+			name:          "Height range with short payload",
+			input:         Frame{Type: MsgHeightRange, Payload: []byte{0x04, 0xf8}}.EncodeNotification(),
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		m, err := Decode(test.input)
+		if test.expectedError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expectedMessage, m, test.name)
+	}
+}
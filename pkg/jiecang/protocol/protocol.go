@@ -0,0 +1,188 @@
+// Package protocol implements the framing of the Jiecang controller UART
+// protocol.
+//
+// Every message exchanged with the controller uses the following format:
+//   - Bytes 0-1: Preamble (0xf1, 0xf1 for commands, 0xf2, 0xf2 for notifications)
+//   - Byte 2: Message type/command
+//   - Byte 3: Data length (number of data bytes)
+//   - Bytes 4..(4+dataLen-1): Data payload
+//   - Byte (len-2): Checksum (sum of type, length, and data bytes, mod 256)
+//   - Byte (len-1): Terminator (0x7e)
+//
+// Frame.Encode builds command frames sent to the controller and Decode turns
+// notifications received from the controller into typed messages.
+package protocol
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// CommandPreamble starts every frame sent to the controller (twice).
+	CommandPreamble = 0xf1
+	// NotificationPreamble starts every frame sent by the controller (twice).
+	NotificationPreamble = 0xf2
+	// Terminator ends every frame.
+	Terminator = 0x7e
+
+	// MinFrameLen is the length of a frame without payload.
+	MinFrameLen = 6
+)
+
+// Commands sent to the controller.
+const (
+	CmdUp               = 0x01 // Move up, like a tap on the up button
+	CmdDown             = 0x02 // Move down, like a tap on the down button
+	CmdSaveMemory1      = 0x03 // Save current height to memory preset 1
+	CmdSaveMemory2      = 0x04 // Save current height to memory preset 2
+	CmdGoToMemory1      = 0x05 // Move to memory preset 1
+	CmdGoToMemory2      = 0x06 // Move to memory preset 2
+	CmdFetchSettings    = 0x07 // Request settings and memory presets
+	CmdFetchHeightRange = 0x0c // Request the physical height range
+	CmdSetUnits         = 0x0e // Set the display units (payload is a Unit)
+	CmdSetMemoryMode    = 0x19 // Set the memory mode (0 one-touch, 1 constant touch)
+	CmdGoToHeight       = 0x1b // Move to the height given in the payload (mm)
+	CmdSetAntiCollision = 0x1d // Set the anti-collision sensitivity (1-3)
+	CmdQueryLimits      = 0x20 // Request the user-defined height limits
+	CmdSetMaxLimit      = 0x21 // Set the current height as upper limit
+	CmdSetMinLimit      = 0x22 // Set the current height as lower limit
+	CmdClearLimits      = 0x23 // Clear the user-defined height limits
+	CmdSaveMemory3      = 0x25 // Save current height to memory preset 3
+	CmdSaveMemory4      = 0x26 // Save current height to memory preset 4
+	CmdGoToMemory3      = 0x27 // Move to memory preset 3
+	CmdGoToMemory4      = 0x28 // Move to memory preset 4
+	CmdStop             = 0x2b // Stop any movement
+	CmdFetchStandTime   = 0xa2 // Request standing time statistics
+	CmdFetchAllTime     = 0xaa // Request total usage time statistics
+)
+
+// Notifications sent by the controller.
+const (
+	MsgHeight        = 0x01 // Current height
+	MsgHeightRange   = 0x07 // Physical height range
+	MsgUnits         = 0x0e // Units setting
+	MsgUnknown17     = 0x17 // Unknown setting, sent along with the settings
+	MsgMemoryMode    = 0x19 // Memory mode setting
+	MsgGoToHeight    = 0x1b // Response to CmdGoToHeight
+	MsgAntiCollision = 0x1d // Anti-collision sensitivity setting
+	MsgLimits        = 0x20 // Which user-defined height limits are set
+	MsgMaxLimit      = 0x21 // User-defined upper height limit
+	MsgMinLimit      = 0x22 // User-defined lower height limit
+	MsgMemoryPreset1 = 0x25 // Height of memory preset 1
+	MsgMemoryPreset2 = 0x26 // Height of memory preset 2
+	MsgMemoryPreset3 = 0x27 // Height of memory preset 3
+	MsgMemoryPreset4 = 0x28 // Height of memory preset 4
+	MsgStandTime     = 0xa2 // Standing time statistics
+	MsgAllTime       = 0xaa // Total usage time statistics
+)
+
+// ErrInvalidFrame is returned when a frame has a wrong preamble, length,
+// checksum or terminator.
+var ErrInvalidFrame = errors.New("invalid frame")
+
+// Frame is a single protocol message, without preamble, length, checksum
+// and terminator.
+type Frame struct {
+	Type    byte
+	Payload []byte
+}
+
+// Encode returns the wire representation of f as a command sent to the
+// controller.
+func (f Frame) Encode() []byte {
+	return f.encode(CommandPreamble)
+}
+
+// EncodeNotification returns the wire representation of f as a notification
+// sent by the controller.
+func (f Frame) EncodeNotification() []byte {
+	return f.encode(NotificationPreamble)
+}
+
+func (f Frame) encode(preamble byte) []byte {
+	buf := make([]byte, 0, MinFrameLen+len(f.Payload))
+	buf = append(buf, preamble, preamble, f.Type, byte(len(f.Payload)))
+	buf = append(buf, f.Payload...)
+	return append(buf, Checksum(f.Type, f.Payload), Terminator)
+}
+
+// String returns the frame type and payload in hex.
+func (f Frame) String() string {
+	return fmt.Sprintf("%02x:%x", f.Type, f.Payload)
+}
+
+// Checksum computes the checksum of a frame: the sum of type, length and
+// payload bytes, mod 256.
+func Checksum(dataType byte, payload []byte) byte {
+	sum := int(dataType) + len(payload)
+	for _, b := range payload {
+		sum += int(b)
+	}
+	return byte(sum % 256)
+}
+
+// ParseNotification validates a notification frame received from the
+// controller and returns its type and payload.
+func ParseNotification(buf []byte) (Frame, error) {
+	return parse(buf, NotificationPreamble)
+}
+
+// ParseCommand validates a command frame sent to the controller and returns
+// its type and payload.
+func ParseCommand(buf []byte) (Frame, error) {
+	return parse(buf, CommandPreamble)
+}
+
+func parse(buf []byte, preamble byte) (Frame, error) {
+	// Check length first to prevent index out of bounds
+	if len(buf) < MinFrameLen {
+		return Frame{}, fmt.Errorf("%w: too short (%d bytes)", ErrInvalidFrame, len(buf))
+	}
+
+	// Check preamble and last byte
+	if buf[0] != preamble || buf[1] != preamble || buf[len(buf)-1] != Terminator {
+		return Frame{}, fmt.Errorf("%w: bad preamble or terminator", ErrInvalidFrame)
+	}
+
+	// Payload and the last two bytes (checksum and terminator) should
+	// account for the whole frame.
+	dataLen := int(buf[3])
+	if len(buf) != MinFrameLen+dataLen {
+		return Frame{}, fmt.Errorf("%w: length %d does not match %d bytes", ErrInvalidFrame, dataLen, len(buf))
+	}
+
+	f := Frame{Type: buf[2], Payload: append([]byte(nil), buf[4:4+dataLen]...)}
+	if Checksum(f.Type, f.Payload) != buf[len(buf)-2] {
+		return Frame{}, fmt.Errorf("%w: bad checksum", ErrInvalidFrame)
+	}
+	return f, nil
+}
+
+// GoToHeight returns the command moving the desk to height (in millimeters).
+func GoToHeight(height uint16) Frame {
+	return Frame{Type: CmdGoToHeight, Payload: []byte{byte(height >> 8), byte(height)}}
+}
+
+// SetAntiCollision returns the command setting the anti-collision
+// sensitivity of the controller to level (1 = High, 2 = Medium, 3 = Low).
+// The controller confirms with an AntiCollision notification.
+func SetAntiCollision(level uint8) Frame {
+	return Frame{Type: CmdSetAntiCollision, Payload: []byte{level}}
+}
+
+// SetMemoryMode returns the command switching the controller to constant
+// touch memory mode if constantTouch is set, or to one-touch mode otherwise.
+func SetMemoryMode(constantTouch bool) Frame {
+	var mode byte
+	if constantTouch {
+		mode = 0x01
+	}
+	return Frame{Type: CmdSetMemoryMode, Payload: []byte{mode}}
+}
+
+// SetUnits returns the command switching the display units of the controller
+// to u. The controller confirms with a Units notification.
+func SetUnits(u Unit) Frame {
+	return Frame{Type: CmdSetUnits, Payload: []byte{byte(u)}}
+}
@@ -0,0 +1,119 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name     string // Name of the testcase
+		input    Frame  // Input
+		expected []byte // Expected result of function
+	}{
+		{
+			name:     "Command without payload",
+			input:    Frame{Type: CmdUp},
+			expected: []byte{0xf1, 0xf1, 0x01, 0x00, 0x01, 0x7e},
+		},
+		{
+			name:     "Stop command",
+			input:    Frame{Type: CmdStop},
+			expected: []byte{0xf1, 0xf1, 0x2b, 0x00, 0x2b, 0x7e},
+		},
+		{
+			name:     "Go to height command",
+			input:    GoToHeight(1070),
+			expected: []byte{0xf1, 0xf1, 0x1b, 0x02, 0x04, 0x2e, 0x4f, 0x7e},
+		},
+		{
+			name:     "Set units command",
+			input:    SetUnits(UnitInches),
+			expected: []byte{0xf1, 0xf1, 0x0e, 0x01, 0x01, 0x10, 0x7e},
+		},
+		{
+			name:     "Set anti-collision command",
+			input:    SetAntiCollision(2),
+			expected: []byte{0xf1, 0xf1, 0x1d, 0x01, 0x02, 0x20, 0x7e},
+		},
+		{
+			name:     "Set memory mode command",
+			input:    SetMemoryMode(true),
+			expected: []byte{0xf1, 0xf1, 0x19, 0x01, 0x01, 0x1b, 0x7e},
+		},
+		{
+			name:     "Checksum overflow",
+			input:    Frame{Type: CmdFetchAllTime, Payload: []byte{0xff}},
+			expected: []byte{0xf1, 0xf1, 0xaa, 0x01, 0xff, 0xaa, 0x7e},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.input.Encode(), test.name)
+	}
+}
+
+func TestEncodeNotification(t *testing.T) {
+	f := Frame{Type: MsgHeight, Payload: []byte{0x03, 0x37, 0x07}}
+	assert.Equal(t, []byte{0xf2, 0xf2, 0x01, 0x03, 0x03, 0x37, 0x07, 0x45, 0x7e}, f.EncodeNotification())
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string // Name of the testcase
+		input         []byte // Input
+		expectedFrame Frame  // Expected frame
+		expectedError bool   // Whether parsing should fail
+	}{
+		{
+			name:          "Valid message for height range",
+			input:         []byte{0xf2, 0xf2, 0x07, 0x04, 0x04, 0xf8, 0x02, 0x6c, 0x75, 0x7e},
+			expectedFrame: Frame{Type: MsgHeightRange, Payload: []byte{0x04, 0xf8, 0x02, 0x6c}},
+		},
+		{
+			name:          "Message with incorrect checksum",
+			input:         []byte{0xf2, 0xf2, 0x07, 0x04, 0x04, 0xf8, 0x02, 0x6c, 0x48, 0x7e},
+			expectedError: true,
+		},
+		{
+			name:          "Command instead of notification",
+			input:         []byte{0xf1, 0xf1, 0x01, 0x00, 0x01, 0x7e},
+			expectedError: true,
+		},
+		{
+			name:          "Message with incorrect length parameter",
+			input:         []byte{0xf2, 0xf2, 0x01, 0x03, 0x04, 0x7e},
+			expectedError: true,
+		},
+		{
+			name:          "Message with extra bytes",
+			input:         []byte{0xf2, 0xf2, 0x0e, 0x01, 0x00, 0x00, 0x0f, 0x7e},
+			expectedError: true,
+		},
+		{
+			name:          "Message that doesn't end with 7e",
+			input:         []byte{0xf2, 0xf2, 0xca, 0xfe},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		f, err := ParseNotification(test.input)
+		if test.expectedError {
+			assert.ErrorIs(t, err, ErrInvalidFrame, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expectedFrame, f, test.name)
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	f, err := ParseCommand(GoToHeight(1070).Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, GoToHeight(1070), f)
+
+	_, err = ParseCommand(Frame{Type: MsgHeight}.EncodeNotification())
+	assert.ErrorIs(t, err, ErrInvalidFrame)
+}
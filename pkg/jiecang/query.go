@@ -0,0 +1,227 @@
+package jiecang
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// This file contains request/response helpers which send a command and wait
+// for the matching notification from the controller.
+
+const (
+	defaultQueryTimeout = time.Second
+	defaultQueryRetries = 3
+
+	// presetSettleTime is how long QueryPresets waits for further presets
+	// after the last one received.
+	presetSettleTime = 200 * time.Millisecond
+)
+
+// ErrTimeout is returned when the controller does not answer a query.
+var ErrTimeout = errors.New("timed out waiting for controller response")
+
+// Range is the height range of the desk.
+type Range struct {
+	Lowest  Height
+	Highest Height
+}
+
+// Presets holds memory preset heights, keyed by memory preset number (1-4).
+// Presets that are not set have a height of 0.
+type Presets map[int]Height
+
+// waiter receives the notifications matching a pending query.
+type waiter struct {
+	match func(protocol.Message) bool
+	ch    chan protocol.Message
+	quiet bool // Whether the next message delivered is kept from FrameReceived
+}
+
+// waiters keeps track of the pending queries.
+type waiters struct {
+	mu   sync.Mutex
+	list []*waiter
+}
+
+// add registers a waiter for the notifications matching match.
+func (w *waiters) add(match func(protocol.Message) bool) *waiter {
+	return w.register(&waiter{match: match, ch: make(chan protocol.Message, 8)})
+}
+
+// addQuiet is like add, but the first message delivered to the waiter is
+// not published as a FrameReceived event, see dispatch.
+func (w *waiters) addQuiet(match func(protocol.Message) bool) *waiter {
+	return w.register(&waiter{match: match, ch: make(chan protocol.Message, 8), quiet: true})
+}
+
+// register registers wt.
+func (w *waiters) register(wt *waiter) *waiter {
+	w.mu.Lock()
+	w.list = append(w.list, wt)
+	w.mu.Unlock()
+	return wt
+}
+
+// remove unregisters wt.
+func (w *waiters) remove(wt *waiter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, v := range w.list {
+		if v == wt {
+			w.list = append(w.list[:i], w.list[i+1:]...)
+			return
+		}
+	}
+}
+
+// dispatch delivers msg to every waiter it matches, and reports whether a
+// quiet waiter took it, see addQuiet. Waiters that are not keeping up miss
+// the message rather than blocking the receiver.
+func (w *waiters) dispatch(msg protocol.Message) (quiet bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, wt := range w.list {
+		if wt.match(msg) {
+			select {
+			case wt.ch <- msg:
+				quiet = quiet || wt.quiet
+				wt.quiet = false
+			default:
+			}
+		}
+	}
+	return quiet
+}
+
+// request sends command and waits for the first notification matching
+// match. The command is resent if no answer arrives within the query
+// timeout, up to the configured number of retries.
+//
+// Returns ErrTimeout if the controller never answers, or ctx.Err() if the
+// context is cancelled.
+func (j *Jiecang) request(ctx context.Context, command protocol.Frame, match func(protocol.Message) bool) (protocol.Message, error) {
+	wt := j.waiters.add(match)
+	defer j.waiters.remove(wt)
+	return j.await(ctx, command, wt)
+}
+
+// await sends command and waits for the first notification delivered to
+// wt, resending the command like request.
+func (j *Jiecang) await(ctx context.Context, command protocol.Frame, wt *waiter) (protocol.Message, error) {
+	for attempt := 0; attempt <= j.queryRetries; attempt++ {
+		if err := j.sendCommand(command); err != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(j.queryTimeout)
+		select {
+		case msg := <-wt.ch:
+			timer.Stop()
+			return msg, nil
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil, fmt.Errorf("command %s: %w", command, ErrTimeout)
+}
+
+// QueryHeightRange requests the minimum and maximum physical height of the
+// desk and waits for the answer. LowestHeight and HighestHeight are updated
+// as well. User-defined limits are not taken into account, see QueryLimits.
+//
+// Returns an error if the command transmission fails, the controller does
+// not answer (ErrTimeout) or ctx is cancelled.
+//
+// Example:
+//
+//	r, err := desk.QueryHeightRange(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%s - %s\n", r.Lowest, r.Highest)
+func (j *Jiecang) QueryHeightRange(ctx context.Context) (Range, error) {
+	msg, err := j.request(ctx, commands["fetch_height_range"], func(m protocol.Message) bool {
+		_, ok := m.(protocol.HeightRange)
+		return ok
+	})
+	if err != nil {
+		return Range{}, err
+	}
+	m := msg.(protocol.HeightRange)
+	lowest, err := j.FromController(m.Lowest)
+	if err != nil {
+		return Range{}, err
+	}
+	highest, err := j.FromController(m.Highest)
+	if err != nil {
+		return Range{}, err
+	}
+	return Range{Lowest: lowest, Highest: highest}, nil
+}
+
+// QueryHeight requests the current height of the desk and waits for the
+// answer.
+//
+// Returns an error if the command transmission fails, the controller does
+// not answer (ErrTimeout) or ctx is cancelled.
+func (j *Jiecang) QueryHeight(ctx context.Context) (Height, error) {
+	msg, err := j.request(ctx, commands["fetch_height"], func(m protocol.Message) bool {
+		_, ok := m.(protocol.HeightReport)
+		return ok
+	})
+	if err != nil {
+		return 0, err
+	}
+	return j.FromController(msg.(protocol.HeightReport).Height)
+}
+
+// QueryPresets requests the heights saved in the memory presets and waits
+// for the answer. The presets stored in the desk state are updated as well.
+//
+// The controller sends one notification per preset, so QueryPresets
+// collects presets until all four are received or none has been received
+// for a short while.
+//
+// Returns an error if the command transmission fails, the controller does
+// not answer (ErrTimeout) or ctx is cancelled.
+func (j *Jiecang) QueryPresets(ctx context.Context) (Presets, error) {
+	isPreset := func(m protocol.Message) bool {
+		_, ok := m.(protocol.MemoryPreset)
+		return ok
+	}
+	// Register before sending, so presets following the first one are
+	// not missed.
+	wt := j.waiters.add(isPreset)
+	defer j.waiters.remove(wt)
+
+	msg, err := j.request(ctx, commands["fetch_height"], isPreset)
+	if err != nil {
+		return nil, err
+	}
+
+	presets := Presets{}
+	for {
+		m := msg.(protocol.MemoryPreset)
+		if presets[m.Slot], err = j.savedFromController(m.Height); err != nil {
+			return nil, err
+		}
+		if len(presets) == 4 {
+			return presets, nil
+		}
+
+		select {
+		case msg = <-wt.ch:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(presetSettleTime):
+			return presets, nil
+		}
+	}
+}
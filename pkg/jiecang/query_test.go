@@ -0,0 +1,78 @@
+package jiecang
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestQueryHeightRange(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	ft.responses[protocol.CmdFetchHeightRange] = [][]byte{reply(0x07, 0x04, 0xb0, 0x02, 0xbc)}
+	r, err := j.QueryHeightRange(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Range{Lowest: 700, Highest: 1200}, r)
+	assert.Equal(t, Height(700), j.LowestHeight)
+	assert.Equal(t, Height(1200), j.HighestHeight)
+}
+
+func TestQueryPresets(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	presets, err := j.QueryPresets(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Presets{1: 1102, 2: 730, 3: 0, 4: 0}, presets)
+}
+
+func TestQueryRetry(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	j.queryTimeout = 10 * time.Millisecond
+
+	// Controller only answers the second attempt
+	delete(ft.responses, protocol.CmdFetchHeightRange)
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if f.count(frame) == 3 {
+			f.notify(reply(0x07, 0x04, 0xf8, 0x02, 0x6c))
+		}
+	}
+
+	r, err := j.QueryHeightRange(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Range{Lowest: 620, Highest: 1272}, r)
+}
+
+func TestQueryTimeout(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	j.queryTimeout = 10 * time.Millisecond
+
+	delete(ft.responses, protocol.CmdFetchHeightRange)
+	_, err = j.QueryHeightRange(context.Background())
+	assert.ErrorIs(t, err, ErrTimeout)
+	// Initial query, first attempt and retries
+	assert.Equal(t, 2+defaultQueryRetries, ft.count(commands["fetch_height_range"].Encode()))
+}
+
+func TestQueryCancelled(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	delete(ft.responses, protocol.CmdFetchSettings)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = j.QueryPresets(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,77 @@
+package jiecang
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// SendRaw sends an arbitrary command to the controller and returns every
+// frame received within waitFor after sending it, decoded or not. This is
+// meant for experimenting with commands not supported by the package; the
+// frames also update the desk state like any other.
+//
+// If ctx is done before waitFor elapses, the frames received so far are
+// returned along with the context error.
+//
+// Example:
+//
+//	// Query the user-defined height limits
+//	replies, err := desk.SendRaw(ctx, protocol.Frame{Type: 0x20}, time.Second)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, r := range replies {
+//	    fmt.Printf("%x\n", r.Raw)
+//	}
+func (j *Jiecang) SendRaw(ctx context.Context, f protocol.Frame, waitFor time.Duration) ([]FrameReceived, error) {
+	return j.SendBytes(ctx, f.Encode(), waitFor)
+}
+
+// SendBytes is SendRaw for data written to the transport exactly as given,
+// without adding or verifying the preamble, length, checksum or terminator.
+// This allows sending frames with a deliberately wrong checksum or variants
+// of the framing.
+//
+// Example:
+//
+//	// Query the height range with a wrong checksum
+//	replies, err := desk.SendBytes(ctx, []byte{0xf1, 0xf1, 0x0c, 0x00, 0x00, 0x7e}, time.Second)
+func (j *Jiecang) SendBytes(ctx context.Context, data []byte, waitFor time.Duration) ([]FrameReceived, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no data to send")
+	}
+
+	subCtx, unsubscribe := context.WithCancel(ctx)
+	defer unsubscribe()
+	events := j.SubscribeFrames(subCtx)
+
+	if err := j.write(data); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(waitFor)
+	defer timer.Stop()
+
+	var replies []FrameReceived
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return replies, ctx.Err()
+				}
+				return replies, fmt.Errorf("desk disconnected")
+			}
+			if r, isFrame := e.(FrameReceived); isFrame {
+				replies = append(replies, r)
+			}
+		case <-ctx.Done():
+			return replies, ctx.Err()
+		case <-timer.C:
+			return replies, nil
+		}
+	}
+}
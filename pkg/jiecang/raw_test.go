@@ -0,0 +1,70 @@
+package jiecang
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestSendRaw(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Controller answers an undocumented command with two frames, one of
+	// them malformed
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == 0x99 {
+			f.notify(reply(0x99, 0x01, 0x02))
+			f.notify(reply(0x01, 0x03))
+		}
+	}
+
+	replies, err := j.SendRaw(context.Background(), protocol.Frame{Type: 0x99, Payload: []byte{0x01}}, 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ft.count([]byte{0xf1, 0xf1, 0x99, 0x01, 0x01, 0x9b, 0x7e}))
+	require.Len(t, replies, 2)
+	assert.Equal(t, protocol.Unknown{Frame: protocol.Frame{Type: 0x99, Payload: []byte{0x01, 0x02}}}, replies[0].Message)
+	assert.Equal(t, reply(0x01, 0x03), replies[1].Raw)
+	assert.Error(t, replies[1].Err)
+}
+
+func TestSendBytes(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Controller answers an undocumented command with a wrong checksum
+	wrong := []byte{0xf1, 0xf1, 0x99, 0x00, 0x00, 0x7e}
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if bytes.Equal(frame, wrong) {
+			f.notify(reply(0x99, 0x01))
+		}
+	}
+
+	replies, err := j.SendBytes(context.Background(), wrong, 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ft.count(wrong))
+	require.Len(t, replies, 1)
+	assert.Equal(t, reply(0x99, 0x01), replies[0].Raw)
+
+	_, err = j.SendBytes(context.Background(), nil, 50*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestSendRawCancelled(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	replies, err := j.SendRaw(ctx, protocol.Frame{Type: 0x99}, time.Minute)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, replies)
+}
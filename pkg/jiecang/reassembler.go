@@ -0,0 +1,113 @@
+package jiecang
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// maxDataLen is the largest data length accepted in a frame. Known messages
+// carry at most a handful of bytes, so a larger length byte means that the
+// preamble was found in garbage and the decoder should resynchronise instead
+// of waiting for a frame that will never complete.
+const maxDataLen = 32
+
+var preamble = []byte{protocol.NotificationPreamble, protocol.NotificationPreamble}
+
+// errOutsideFrame is the reason bytes found outside of any frame are
+// discarded.
+var errOutsideFrame = fmt.Errorf("%w: data outside of a frame", protocol.ErrInvalidFrame)
+
+// reassembler extracts complete frames from the stream of bytes received
+// from the controller.
+//
+// Data may arrive in arbitrary chunks: a frame can be split across several
+// notifications and a single notification can contain several frames.
+// reassembler buffers partial input, locates the 0xf2 0xf2 preamble, uses
+// the length byte to find where the frame ends (see the protocol package
+// for the format) and skips anything protocol.ParseNotification rejects.
+//
+// A reassembler is not safe for concurrent use.
+type reassembler struct {
+	buf []byte
+
+	// dropped, if not nil, is called by Write with every run of bytes
+	// skipped because it is not part of a valid frame, and why the run was
+	// skipped. Runs are reported before the frames returned by the same
+	// Write. data does not alias internal buffers.
+	dropped func(data []byte, err error)
+}
+
+// Write appends p to the buffered input and returns all the complete, valid
+// frames found so far. Returned frames do not alias p or internal buffers.
+func (r *reassembler) Write(p []byte) [][]byte {
+	r.buf = append(r.buf, p...)
+
+	// Consecutive skipped bytes are reported as a single run
+	var skipped []byte
+	var reason error
+	skip := func(n int, err error) {
+		if n == 0 {
+			return
+		}
+		if reason == nil {
+			reason = err
+		}
+		skipped = append(skipped, r.buf[:n]...)
+		r.buf = r.buf[n:]
+	}
+	flush := func() {
+		if len(skipped) > 0 && r.dropped != nil {
+			r.dropped(skipped, reason)
+		}
+		skipped, reason = nil, nil
+	}
+	defer flush()
+
+	var frames [][]byte
+	for {
+		start := bytes.Index(r.buf, preamble)
+		if start < 0 {
+			// Keep a trailing 0xf2, it might be the start of a preamble
+			n := len(r.buf)
+			if n > 0 && r.buf[n-1] == preamble[0] {
+				n--
+			}
+			skip(n, errOutsideFrame)
+			return frames
+		}
+		skip(start, errOutsideFrame)
+
+		// Wait for the length byte
+		if len(r.buf) < 4 {
+			return frames
+		}
+		dataLen := int(r.buf[3])
+		if dataLen > maxDataLen {
+			skip(1, fmt.Errorf("%w: implausible length %d", protocol.ErrInvalidFrame, dataLen))
+			continue
+		}
+
+		// Wait for the rest of the frame
+		frameLen := dataLen + 6
+		if len(r.buf) < frameLen {
+			return frames
+		}
+
+		frame := r.buf[:frameLen]
+		if _, err := protocol.ParseNotification(frame); err != nil {
+			// Not a frame, resynchronise on the next preamble
+			skip(1, err)
+			continue
+		}
+		flush()
+		frames = append(frames, bytes.Clone(frame))
+		r.buf = r.buf[frameLen:]
+	}
+}
+
+// Reset discards any buffered partial input.
+func (r *reassembler) Reset() {
+	r.buf = r.buf[:0]
+}
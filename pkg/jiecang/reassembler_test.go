@@ -0,0 +1,106 @@
+package jiecang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestReassembler(t *testing.T) {
+	height := []byte{0xf2, 0xf2, 0x01, 0x03, 0x03, 0x37, 0x07, 0x45, 0x7e}
+	heightRange := []byte{0xf2, 0xf2, 0x07, 0x04, 0x04, 0xf8, 0x02, 0x6c, 0x75, 0x7e}
+	// Height of 0x027e mm, low byte equal to the terminator
+	heightWithTerminator := reply(0x01, 0x02, 0x7e, 0x00)
+
+	tests := []struct {
+		name            string   // Name of the testcase
+		input           [][]byte // Chunks of input, in order
+		expectedFrames  [][]byte // Expected frames
+		expectedDropped [][]byte // Expected runs of skipped bytes
+	}{
+		{
+			name:           "Single frame",
+			input:          [][]byte{height},
+			expectedFrames: [][]byte{height},
+		},
+		{
+			name:           "Multiple frames in one chunk",
+			input:          [][]byte{append(append([]byte{}, height...), heightRange...)},
+			expectedFrames: [][]byte{height, heightRange},
+		},
+		{
+			name:           "Frame split across chunks",
+			input:          [][]byte{height[:3], height[3:6], height[6:]},
+			expectedFrames: [][]byte{height},
+		},
+		{
+			name:           "Frame split within preamble",
+			input:          [][]byte{height[:1], height[1:]},
+			expectedFrames: [][]byte{height},
+		},
+		{
+			name:           "Data byte equal to terminator",
+			input:          [][]byte{heightWithTerminator},
+			expectedFrames: [][]byte{heightWithTerminator},
+		},
+		{
+			name:            "Garbage before frame",
+			input:           [][]byte{append([]byte{0xde, 0xad, 0x7e, 0xf2}, height...)},
+			expectedFrames:  [][]byte{height},
+			expectedDropped: [][]byte{{0xde, 0xad, 0x7e, 0xf2}},
+		},
+		{
+			name: "Resynchronise after invalid frame",
+			input: [][]byte{
+				{0xf2, 0xf2, 0x01, 0x03, 0x03, 0x37, 0x07, 0x46, 0x7e},
+				heightRange,
+			},
+			expectedFrames:  [][]byte{heightRange},
+			expectedDropped: [][]byte{{0xf2, 0xf2, 0x01, 0x03, 0x03, 0x37, 0x07, 0x46, 0x7e}},
+		},
+		{
+			name: "Invalid frame between frames",
+			input: [][]byte{
+				append(append(append([]byte{}, height...), 0xf2, 0xf2, 0x01, 0x00, 0x00, 0x7e), heightRange...),
+			},
+			expectedFrames:  [][]byte{height, heightRange},
+			expectedDropped: [][]byte{{0xf2, 0xf2, 0x01, 0x00, 0x00, 0x7e}},
+		},
+		{
+			name:            "Preamble with implausible length",
+			input:           [][]byte{append([]byte{0xf2, 0xf2, 0xff, 0xff}, height...)},
+			expectedFrames:  [][]byte{height},
+			expectedDropped: [][]byte{{0xf2, 0xf2, 0xff, 0xff}},
+		},
+		{
+			name:           "Incomplete frame",
+			input:          [][]byte{height[:7]},
+			expectedFrames: nil,
+		},
+	}
+
+	for _, test := range tests {
+		var dropped [][]byte
+		r := reassembler{dropped: func(data []byte, err error) {
+			assert.ErrorIs(t, err, protocol.ErrInvalidFrame, test.name)
+			dropped = append(dropped, data)
+		}}
+		var frames [][]byte
+		for _, chunk := range test.input {
+			frames = append(frames, r.Write(chunk)...)
+		}
+		assert.Equal(t, test.expectedFrames, frames, test.name)
+		assert.Equal(t, test.expectedDropped, dropped, test.name)
+	}
+}
+
+func TestReassemblerReset(t *testing.T) {
+	height := []byte{0xf2, 0xf2, 0x01, 0x03, 0x03, 0x37, 0x07, 0x45, 0x7e}
+
+	var r reassembler
+	assert.Empty(t, r.Write(height[:5]))
+	r.Reset()
+	assert.Empty(t, r.Write(height[5:]))
+	assert.Equal(t, [][]byte{height}, r.Write(height))
+}
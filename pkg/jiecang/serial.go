@@ -0,0 +1,84 @@
+package jiecang
+
+import (
+	"fmt"
+	"sync"
+
+	"go.bug.st/serial"
+)
+
+// DefaultBaudRate is the baud rate used by Jiecang controllers on their
+// UART (RJ45/RJ12) port.
+const DefaultBaudRate = 9600
+
+// SerialTransport is a Transport that talks to the controller directly over
+// its UART port, e.g. through a USB-TTL adapter (/dev/ttyUSB0).
+//
+// The BLE module only tunnels the UART protocol, so frames are exchanged
+// unmodified.
+type SerialTransport struct {
+	port serial.Port
+
+	wg        sync.WaitGroup // Tracks the reader goroutine
+	closeOnce sync.Once
+}
+
+// NewSerialTransport opens the serial port name using baud rate baud (8N1).
+// If baud is 0, DefaultBaudRate is used.
+//
+// Returns an error if the port cannot be opened.
+func NewSerialTransport(name string, baud int) (*SerialTransport, error) {
+	if baud == 0 {
+		baud = DefaultBaudRate
+	}
+
+	port, err := serial.Open(name, &serial.Mode{
+		BaudRate: baud,
+		DataBits: 8,
+		Parity:   serial.NoParity,
+		StopBits: serial.OneStopBit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open serial port %s: %w", name, err)
+	}
+	return &SerialTransport{port: port}, nil
+}
+
+// Write sends frame to the serial port.
+func (t *SerialTransport) Write(frame []byte) error {
+	_, err := t.port.Write(frame)
+	return err
+}
+
+// Subscribe starts reading from the serial port in the background and
+// forwards everything received to handler, until the port is closed or
+// fails.
+func (t *SerialTransport) Subscribe(handler func(buf []byte)) error {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		buf := make([]byte, 256)
+		for {
+			n, err := t.port.Read(buf)
+			if n > 0 {
+				handler(append([]byte(nil), buf[:n]...))
+			}
+			if err != nil {
+				// Port closed, or no longer usable, e.g. adapter unplugged,
+				// in which case writes fail as well. Closing is left to Close.
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Close closes the serial port and waits for the reader to stop.
+func (t *SerialTransport) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		err = t.port.Close()
+		t.wg.Wait()
+	})
+	return err
+}
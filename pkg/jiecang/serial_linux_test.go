@@ -0,0 +1,72 @@
+//go:build linux
+
+package jiecang
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// openPty opens a pseudo-terminal pair and returns the master side along
+// with the path of the slave device.
+func openPty(t *testing.T) (*os.File, string) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+	t.Cleanup(func() { _ = master.Close() })
+
+	fd := int(master.Fd())
+	require.NoError(t, unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0))
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	require.NoError(t, err)
+	return master, "/dev/pts/" + strconv.Itoa(n)
+}
+
+func TestSerialTransport(t *testing.T) {
+	master, name := openPty(t)
+
+	st, err := NewSerialTransport(name, 0)
+	require.NoError(t, err)
+
+	received := make(chan []byte, 16)
+	require.NoError(t, st.Subscribe(func(buf []byte) { received <- buf }))
+
+	// Commands are written unmodified to the UART
+	require.NoError(t, st.Write(commands["fetch_height_range"].Encode()))
+	buf := make([]byte, 64)
+	n, err := master.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, commands["fetch_height_range"].Encode(), buf[:n])
+
+	// Notifications from the controller reach the handler
+	notification := reply(0x07, 0x04, 0xf8, 0x02, 0x6c)
+	_, err = master.Write(notification)
+	require.NoError(t, err)
+
+	var got []byte
+	timeout := time.After(time.Second)
+	for len(got) < len(notification) {
+		select {
+		case buf := <-received:
+			got = append(got, buf...)
+		case <-timeout:
+			t.Fatalf("notification not received, got %x", got)
+		}
+	}
+	assert.Equal(t, notification, got)
+
+	require.NoError(t, st.Close())
+	require.NoError(t, st.Close())
+}
+
+func TestSerialTransportInvalidPort(t *testing.T) {
+	_, err := NewSerialTransport("/dev/nonexistent-tty", 9600)
+	assert.Error(t, err)
+}
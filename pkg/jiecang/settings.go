@@ -0,0 +1,216 @@
+package jiecang
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// This file contains functions for changing the settings of the controller.
+
+// Anti-collision sensitivity levels.
+const (
+	AntiCollisionHigh   uint8 = 1
+	AntiCollisionMedium uint8 = 2
+	AntiCollisionLow    uint8 = 3
+)
+
+// antiCollisionLevels holds the names of the anti-collision sensitivity
+// levels.
+var antiCollisionLevels = map[uint8]string{
+	AntiCollisionHigh:   "high",
+	AntiCollisionMedium: "medium",
+	AntiCollisionLow:    "low",
+}
+
+// ParseAntiCollisionSensitivity parses the name of an anti-collision
+// sensitivity level: "high", "medium" or "low".
+func ParseAntiCollisionSensitivity(s string) (uint8, error) {
+	for level, name := range antiCollisionLevels {
+		if strings.EqualFold(s, name) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown anti-collision sensitivity %q (valid: high, medium, low)", s)
+}
+
+// AntiCollisionSensitivityName returns the name of an anti-collision
+// sensitivity level ("high", "medium" or "low").
+func AntiCollisionSensitivityName(level uint8) string {
+	if name, ok := antiCollisionLevels[level]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", level)
+}
+
+// MemoryMode is the way memory presets are recalled from the control panel.
+type MemoryMode uint8
+
+const (
+	// MemoryModeOneTouch moves the desk to a memory preset with a single
+	// press of the memory button.
+	MemoryModeOneTouch MemoryMode = iota
+	// MemoryModeConstantTouch moves the desk to a memory preset only while
+	// the memory button is held.
+	MemoryModeConstantTouch
+)
+
+// ParseMemoryMode parses the name of a memory mode: "one-touch" or
+// "constant".
+func ParseMemoryMode(s string) (MemoryMode, error) {
+	switch strings.ToLower(s) {
+	case "one-touch":
+		return MemoryModeOneTouch, nil
+	case "constant", "constant-touch":
+		return MemoryModeConstantTouch, nil
+	}
+	return 0, fmt.Errorf("unknown memory mode %q (valid: one-touch, constant)", s)
+}
+
+// String returns the name of m ("one-touch" or "constant").
+func (m MemoryMode) String() string {
+	switch m {
+	case MemoryModeOneTouch:
+		return "one-touch"
+	case MemoryModeConstantTouch:
+		return "constant"
+	}
+	return fmt.Sprintf("MemoryMode(%d)", uint8(m))
+}
+
+// MemoryMode returns the memory mode of the controller, as reported by
+// MemoryConstantTouchMode.
+func (j *Jiecang) MemoryMode() MemoryMode {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if j.MemoryConstantTouchMode {
+		return MemoryModeConstantTouch
+	}
+	return MemoryModeOneTouch
+}
+
+// SetMemoryMode sets the memory mode of the controller to mode. The setting
+// is then read back from the controller until it reports mode, which
+// confirms the change. MemoryConstantTouchMode is updated as well.
+//
+// Returns an error if mode is not valid, the command transmission fails,
+// the controller does not report the new setting (ErrTimeout) or ctx is
+// cancelled. Fails with ErrUnsupported if the profile of the controller does
+// not support it, see WithProfile.
+//
+// Example:
+//
+//	if err := desk.SetMemoryMode(ctx, jiecang.MemoryModeOneTouch); err != nil {
+//	    log.Fatal(err)
+//	}
+func (j *Jiecang) SetMemoryMode(ctx context.Context, mode MemoryMode) error {
+	if mode != MemoryModeOneTouch && mode != MemoryModeConstantTouch {
+		return fmt.Errorf("invalid memory mode %s", mode)
+	}
+	if err := j.supports("memory mode setting", func(p Profile) bool { return p.MemoryMode }); err != nil {
+		return err
+	}
+	constantTouch := mode == MemoryModeConstantTouch
+
+	if err := j.sendCommand(protocol.SetMemoryMode(constantTouch)); err != nil {
+		return err
+	}
+
+	// Read the setting back, the fetch is repeated until the controller
+	// reports the new mode.
+	_, err := j.request(ctx, commands["fetch_height"], func(m protocol.Message) bool {
+		mm, ok := m.(protocol.MemoryMode)
+		return ok && mm.ConstantTouch == constantTouch
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set memory mode to %s: %w", mode, err)
+	}
+	return nil
+}
+
+// SetAntiCollisionSensitivity sets the anti-collision sensitivity of the
+// controller to level and waits for the controller to confirm the change.
+// AntiCollisionSensitivity is updated as well.
+//
+// Parameters:
+//   - level: AntiCollisionHigh, AntiCollisionMedium or AntiCollisionLow
+//
+// Returns an error if level is not valid, the command transmission fails,
+// the controller does not confirm the change (ErrTimeout) or ctx is
+// cancelled. Fails with ErrUnsupported if the profile of the controller does
+// not support it, see WithProfile.
+//
+// Example:
+//
+//	if err := desk.SetAntiCollisionSensitivity(ctx, jiecang.AntiCollisionLow); err != nil {
+//	    log.Fatal(err)
+//	}
+func (j *Jiecang) SetAntiCollisionSensitivity(ctx context.Context, level uint8) error {
+	if _, ok := antiCollisionLevels[level]; !ok {
+		return fmt.Errorf("invalid anti-collision sensitivity %d (must be 1-3)", level)
+	}
+	if err := j.supports("anti-collision setting", func(p Profile) bool { return p.AntiCollision }); err != nil {
+		return err
+	}
+
+	_, err := j.request(ctx, protocol.SetAntiCollision(level), func(m protocol.Message) bool {
+		ac, ok := m.(protocol.AntiCollision)
+		return ok && ac.Sensitivity == level
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set anti-collision sensitivity to %s: %w", AntiCollisionSensitivityName(level), err)
+	}
+	return nil
+}
+
+// SetUnits switches the display units of the controller to u and waits for
+// the controller to confirm the change. Units is updated as well.
+//
+// Returns an error if u is not a valid unit, the command transmission fails,
+// the controller does not confirm the change (ErrTimeout) or ctx is
+// cancelled. Fails with ErrUnsupported if the profile of the controller does
+// not support it, see WithProfile.
+//
+// Example:
+//
+//	if err := desk.SetUnits(ctx, jiecang.UnitInches); err != nil {
+//	    log.Fatal(err)
+//	}
+func (j *Jiecang) SetUnits(ctx context.Context, u Unit) error {
+	if u != UnitCentimeters && u != UnitInches {
+		return fmt.Errorf("invalid unit %s", u)
+	}
+	if err := j.supports("units setting", func(p Profile) bool { return p.Units }); err != nil {
+		return err
+	}
+
+	_, err := j.request(ctx, protocol.SetUnits(protocol.Unit(u)), func(m protocol.Message) bool {
+		units, ok := m.(protocol.Units)
+		return ok && Unit(units.Unit) == u
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set units to %s: %w", u, err)
+	}
+	return nil
+}
+
+// SetDisplayUnits sets the units used for the heights in error messages and
+// logs. By default these follow the Units setting of the controller.
+func (j *Jiecang) SetDisplayUnits(u Unit) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.displayUnits = u
+	j.displayOverride = true
+}
+
+// format formats h in the display units.
+func (j *Jiecang) format(h Height) string {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if j.displayOverride {
+		return h.Format(j.displayUnits)
+	}
+	return h.Format(j.Units)
+}
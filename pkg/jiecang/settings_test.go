@@ -0,0 +1,159 @@
+package jiecang
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestSetMemoryMode(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Controller reports the memory mode along with the other settings
+	mode := byte(0x00)
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		switch frame[2] {
+		case protocol.CmdSetMemoryMode:
+			mode = frame[4]
+		case protocol.CmdFetchSettings:
+			f.notify(reply(0x19, mode))
+		}
+	}
+
+	require.NoError(t, j.SetMemoryMode(context.Background(), MemoryModeConstantTouch))
+	assert.True(t, j.MemoryConstantTouchMode)
+	assert.Equal(t, MemoryModeConstantTouch, j.MemoryMode())
+	assert.Equal(t, 1, ft.count([]byte{0xf1, 0xf1, 0x19, 0x01, 0x01, 0x1b, 0x7e}))
+
+	require.NoError(t, j.SetMemoryMode(context.Background(), MemoryModeOneTouch))
+	assert.False(t, j.MemoryConstantTouchMode)
+	assert.Equal(t, MemoryModeOneTouch, j.MemoryMode())
+	assert.Equal(t, 1, ft.count([]byte{0xf1, 0xf1, 0x19, 0x01, 0x00, 0x1a, 0x7e}))
+}
+
+func TestSetMemoryModeNotConfirmed(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	j.queryTimeout = 10 * time.Millisecond
+
+	// Controller ignores the command
+	ft.responses[protocol.CmdFetchSettings] = [][]byte{reply(0x19, 0x00)}
+
+	err = j.SetMemoryMode(context.Background(), MemoryModeConstantTouch)
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.False(t, j.MemoryConstantTouchMode)
+}
+
+func TestParseMemoryMode(t *testing.T) {
+	tests := []struct {
+		name     string     // Name of the testcase
+		input    string     // Input
+		expected MemoryMode // Expected result of function
+		err      bool       // Whether an error is expected
+	}{
+		{name: "One-touch", input: "one-touch", expected: MemoryModeOneTouch},
+		{name: "Constant", input: "constant", expected: MemoryModeConstantTouch},
+		{name: "Long name", input: "Constant-Touch", expected: MemoryModeConstantTouch},
+		{name: "Unknown mode", input: "toggle", err: true},
+	}
+
+	for _, test := range tests {
+		mode, err := ParseMemoryMode(test.input)
+		if test.err {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, mode, test.name)
+	}
+}
+
+func TestSetAntiCollisionSensitivity(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Controller confirms the new setting
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == protocol.CmdSetAntiCollision {
+			f.notify(reply(0x1d, frame[4]))
+		}
+	}
+
+	require.NoError(t, j.SetAntiCollisionSensitivity(context.Background(), AntiCollisionLow))
+	assert.Equal(t, AntiCollisionLow, j.AntiCollisionSensitivity)
+	assert.Equal(t, 1, ft.count([]byte{0xf1, 0xf1, 0x1d, 0x01, 0x03, 0x21, 0x7e}))
+
+	assert.Error(t, j.SetAntiCollisionSensitivity(context.Background(), 0))
+	assert.Error(t, j.SetAntiCollisionSensitivity(context.Background(), 4))
+}
+
+func TestParseAntiCollisionSensitivity(t *testing.T) {
+	tests := []struct {
+		name     string // Name of the testcase
+		input    string // Input
+		expected uint8  // Expected result of function
+		err      bool   // Whether an error is expected
+	}{
+		{name: "High", input: "high", expected: AntiCollisionHigh},
+		{name: "Medium", input: "Medium", expected: AntiCollisionMedium},
+		{name: "Low", input: "LOW", expected: AntiCollisionLow},
+		{name: "Unknown level", input: "off", err: true},
+	}
+
+	for _, test := range tests {
+		level, err := ParseAntiCollisionSensitivity(test.input)
+		if test.err {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, level, test.name)
+		assert.Equal(t, strings.ToLower(test.input), AntiCollisionSensitivityName(level), test.name)
+	}
+}
+
+func TestSetUnits(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	// Controller confirms the new setting
+	ft.onWrite = func(f *fakeTransport, frame []byte) {
+		if frame[2] == protocol.CmdSetUnits {
+			f.notify(reply(0x0e, frame[4]))
+		}
+	}
+
+	require.NoError(t, j.SetUnits(context.Background(), UnitInches))
+	assert.Equal(t, UnitInches, j.Units)
+	assert.Equal(t, 1, ft.count([]byte{0xf1, 0xf1, 0x0e, 0x01, 0x01, 0x10, 0x7e}))
+	assert.Equal(t, "42.3 in", j.format(1075))
+
+	j.SetDisplayUnits(UnitCentimeters)
+	assert.Equal(t, "107.5 cm", j.format(1075))
+}
+
+func TestSetUnitsNotConfirmed(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	j.queryTimeout = 10 * time.Millisecond
+
+	// Controller keeps reporting the old setting
+	ft.responses[protocol.CmdSetUnits] = [][]byte{reply(0x0e, 0x00)}
+
+	err = j.SetUnits(context.Background(), UnitInches)
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, UnitCentimeters, j.Units)
+
+	assert.Error(t, j.SetUnits(context.Background(), Unit(7)))
+}
@@ -0,0 +1,194 @@
+package sim_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang"
+	"github.com/tzermias/deskctl/pkg/jiecang/sim"
+)
+
+// TestJiecang drives a simulated desk end-to-end through the jiecang package.
+func TestJiecang(t *testing.T) {
+	desk := sim.New(sim.Config{
+		Presets:      [4]int{1100, 730, 0, 1000},
+		Speed:        1000,
+		TickInterval: 5 * time.Millisecond,
+	})
+	j, err := jiecang.New(desk)
+	require.NoError(t, err)
+	defer func() { _ = j.Disconnect() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := j.GoToHeight(ctx, jiecang.Centimeters(85.3))
+	require.NoError(t, err)
+	assert.Equal(t, jiecang.Arrived, res.Outcome)
+	assert.Equal(t, 853, desk.Height())
+
+	_, err = j.GoToMemory(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1100, desk.Height())
+
+	assert.Equal(t, 4, j.MemoryPresets())
+	_, err = j.GoToMemory(ctx, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, desk.Height())
+}
+
+// TestJiecangMemory4Unset connects to a simulated desk with memory preset 4
+// not set yet.
+func TestJiecangMemory4Unset(t *testing.T) {
+	desk := sim.New(sim.Config{
+		Presets:       [4]int{1100, 730, 0, 0},
+		MemoryPresets: 4,
+	})
+	j, err := jiecang.New(desk)
+	require.NoError(t, err)
+	defer func() { _ = j.Disconnect() }()
+
+	assert.Equal(t, 4, j.MemoryPresets())
+	_, err = j.GoToMemory(context.Background(), 4)
+	assert.ErrorContains(t, err, "memory 4 is not set")
+	assert.False(t, desk.Moving())
+
+	// Saving is the only way to set it
+	require.NoError(t, j.SaveMemory(4))
+	assert.Eventually(t, func() bool {
+		presets, err := j.QueryPresets(context.Background())
+		return err == nil && presets[4] == 750
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestJiecangSettings changes the settings of a simulated desk.
+func TestJiecangSettings(t *testing.T) {
+	desk := sim.New(sim.Config{})
+	j, err := jiecang.New(desk)
+	require.NoError(t, err)
+	defer func() { _ = j.Disconnect() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	assert.Equal(t, jiecang.UnitCentimeters, j.Units)
+	require.NoError(t, j.SetUnits(ctx, jiecang.UnitInches))
+	assert.Equal(t, jiecang.UnitInches, j.Units)
+
+	require.NoError(t, j.SetAntiCollisionSensitivity(ctx, jiecang.AntiCollisionLow))
+	assert.Equal(t, jiecang.AntiCollisionLow, j.AntiCollisionSensitivity)
+
+	require.NoError(t, j.SetMemoryMode(ctx, jiecang.MemoryModeConstantTouch))
+	assert.Equal(t, jiecang.MemoryModeConstantTouch, j.MemoryMode())
+	require.NoError(t, j.SetMemoryMode(ctx, jiecang.MemoryModeOneTouch))
+	assert.Equal(t, jiecang.MemoryModeOneTouch, j.MemoryMode())
+}
+
+// TestJiecangLimits programs user limits into a simulated desk.
+func TestJiecangLimits(t *testing.T) {
+	desk := sim.New(sim.Config{
+		Height:       900,
+		Speed:        1000,
+		TickInterval: 5 * time.Millisecond,
+	})
+	j, err := jiecang.New(desk)
+	require.NoError(t, err)
+	defer func() { _ = j.Disconnect() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, j.SetLimits(ctx, 70*jiecang.Centimeter, 115*jiecang.Centimeter))
+	assert.Equal(t, jiecang.Limits{Min: 700, Max: 1150}, j.UserLimits)
+	assert.InDelta(t, 900, desk.Height(), 2)
+
+	limits, err := j.QueryLimits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, jiecang.Limits{Min: 700, Max: 1150}, limits)
+
+	// Replacing the upper limit keeps the lower one
+	require.NoError(t, j.SetLimits(ctx, 0, 110*jiecang.Centimeter))
+	limits, err = j.QueryLimits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, jiecang.Limits{Min: 700, Max: 1100}, limits)
+	assert.InDelta(t, 900, desk.Height(), 2)
+	_, err = j.GoToHeight(ctx, 120*jiecang.Centimeter)
+	assert.Error(t, err)
+
+	require.NoError(t, j.ClearLimits(ctx))
+	res, err := j.GoToHeight(ctx, 120*jiecang.Centimeter)
+	require.NoError(t, err)
+	assert.Equal(t, jiecang.Arrived, res.Outcome)
+}
+
+// TestJiecangProbe probes a simulated desk with three memory presets.
+func TestJiecangProbe(t *testing.T) {
+	desk := sim.New(sim.Config{MemoryPresets: 3})
+	j, err := jiecang.New(desk)
+	require.NoError(t, err)
+	defer func() { _ = j.Disconnect() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	profile, err := j.Probe(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, profile.MemoryPresets)
+	assert.True(t, profile.Units)
+	assert.True(t, profile.MemoryMode)
+	assert.True(t, profile.AntiCollision)
+	assert.True(t, profile.Limits)
+	assert.True(t, profile.Stats)
+}
+
+// TestJiecangMoveBy holds the buttons of a simulated desk.
+func TestJiecangMoveBy(t *testing.T) {
+	desk := sim.New(sim.Config{
+		Height:       800,
+		Speed:        100,
+		TickInterval: 10 * time.Millisecond,
+	})
+	j, err := jiecang.New(desk)
+	require.NoError(t, err)
+	defer func() { _ = j.Disconnect() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Longer than the hold timeout of the desk
+	res, err := j.MoveBy(ctx, 80)
+	require.NoError(t, err)
+	assert.Equal(t, jiecang.Arrived, res.Outcome)
+	assert.InDelta(t, 880, desk.Height(), 3)
+
+	res, err = j.MoveFor(ctx, jiecang.DirectionDown, 500*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, jiecang.Arrived, res.Outcome)
+	assert.InDelta(t, 830, desk.Height(), 10)
+}
+
+func TestJiecangCalibration(t *testing.T) {
+	desk := sim.New(sim.Config{
+		Height:       800,
+		Speed:        100,
+		TickInterval: 10 * time.Millisecond,
+	})
+	j, err := jiecang.New(desk, jiecang.WithCalibration(15))
+	require.NoError(t, err)
+	defer func() { _ = j.Disconnect() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	h, err := j.QueryHeight(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, jiecang.Height(815), h)
+
+	res, err := j.GoToHeight(ctx, 900)
+	require.NoError(t, err)
+	assert.Equal(t, jiecang.Arrived, res.Outcome)
+	assert.InDelta(t, 885, desk.Height(), 2)
+}
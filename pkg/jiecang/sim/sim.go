@@ -0,0 +1,485 @@
+// Package sim provides an in-process simulation of a Jiecang desk controller.
+//
+// A Desk emulates the controller side of the UART protocol: it accepts the
+// 0xF1 command frames sent by the jiecang package, moves a virtual desk at a
+// configurable speed within its height range and answers with the same 0xF2
+// notifications a real controller would send. Desk implements the
+// jiecang.Transport interface, so it can be plugged straight into
+// jiecang.New for tests and demos that have no access to hardware.
+//
+// Example usage:
+//
+//	desk := sim.New(sim.Config{})
+//	j, err := jiecang.New(desk)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer j.Disconnect()
+package sim
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// Default values used for zero fields of Config.
+const (
+	DefaultHeight        = 750  // mm
+	DefaultLowestHeight  = 620  // mm
+	DefaultHighestHeight = 1270 // mm
+	DefaultSpeed         = 35   // mm per second
+	DefaultTickInterval  = 100 * time.Millisecond
+	DefaultHoldTimeout   = 500 * time.Millisecond
+)
+
+// Memory preset number of each save/go to memory command.
+var (
+	saveMemoryCommands = map[byte]int{
+		protocol.CmdSaveMemory1: 1,
+		protocol.CmdSaveMemory2: 2,
+		protocol.CmdSaveMemory3: 3,
+		protocol.CmdSaveMemory4: 4,
+	}
+	goToMemoryCommands = map[byte]int{
+		protocol.CmdGoToMemory1: 1,
+		protocol.CmdGoToMemory2: 2,
+		protocol.CmdGoToMemory3: 3,
+		protocol.CmdGoToMemory4: 4,
+	}
+)
+
+// ErrClosed is returned when writing to a Desk that has been closed.
+var ErrClosed = errors.New("sim: desk is closed")
+
+// Config holds the initial state and timing of a simulated desk.
+// All heights are in millimeters. Zero values are replaced with defaults.
+type Config struct {
+	// Height is the initial height of the desk.
+	Height int
+
+	// LowestHeight and HighestHeight define the physical range of the desk.
+	LowestHeight  int
+	HighestHeight int
+
+	// MinLimit and MaxLimit are user-defined limits restricting the range
+	// of the desk. A zero value means that the limit is not set.
+	MinLimit int
+	MaxLimit int
+
+	// Presets holds the heights of memory presets 1-4. A zero value
+	// means that the preset is not set.
+	Presets [4]int
+
+	// MemoryPresets is the number of memory presets of the controller
+	// (3 or 4). Defaults to 4. Commands for presets above it are ignored
+	// and only the available presets are reported.
+	MemoryPresets int
+
+	// Units is the unit setting reported by the controller
+	// (0x00 = cm, 0x01 = inches).
+	Units byte
+
+	// MemoryConstantTouchMode makes memory presets require the command to
+	// be repeated while the desk moves, like a held button.
+	MemoryConstantTouchMode bool
+
+	// AntiCollisionSensitivity is the reported sensitivity
+	// (1 = High, 2 = Medium, 3 = Low). Defaults to 2.
+	AntiCollisionSensitivity byte
+
+	// StandingTime and TotalTime are the usage statistics reported by
+	// the controller, with minute resolution.
+	StandingTime time.Duration
+	TotalTime    time.Duration
+
+	// Speed is the travel speed of the desk in millimeters per second.
+	Speed int
+
+	// TickInterval is how often the desk position is updated and
+	// reported while moving.
+	TickInterval time.Duration
+
+	// HoldTimeout is how long a movement command keeps the desk moving
+	// before it has to be repeated, emulating a held button.
+	HoldTimeout time.Duration
+}
+
+// Desk is a simulated Jiecang controller.
+//
+// Desk is safe for concurrent use. Notifications are delivered to the
+// subscribed handler from a dedicated goroutine, in order.
+type Desk struct {
+	mu  sync.Mutex
+	cfg Config
+
+	height    int       // Current height in mm
+	target    int       // Target height in mm while moving
+	moving    bool      // Whether the desk is moving towards target
+	holdUntil time.Time // Movement stops when not renewed by then; zero for one-touch
+	position  float64   // Fractional position used to apply speed per tick
+
+	handlerMu sync.Mutex // Protects handler, separately from mu so delivery never blocks on mu
+	handler   func(buf []byte)
+
+	queue  [][]byte      // Notifications not delivered yet, protected by mu
+	queued chan struct{} // Wakes up deliver when notifications are queued
+	done   chan struct{}
+	closed bool
+}
+
+// New creates a simulated desk with the given configuration and starts its
+// motion loop. Close must be called to release it.
+func New(cfg Config) *Desk {
+	if cfg.LowestHeight == 0 {
+		cfg.LowestHeight = DefaultLowestHeight
+	}
+	if cfg.HighestHeight == 0 {
+		cfg.HighestHeight = DefaultHighestHeight
+	}
+	if cfg.Height == 0 {
+		cfg.Height = DefaultHeight
+	}
+	if cfg.MemoryPresets == 0 || cfg.MemoryPresets > len(cfg.Presets) {
+		cfg.MemoryPresets = len(cfg.Presets)
+	}
+	if cfg.AntiCollisionSensitivity == 0 {
+		cfg.AntiCollisionSensitivity = 2
+	}
+	if cfg.Speed == 0 {
+		cfg.Speed = DefaultSpeed
+	}
+	if cfg.TickInterval == 0 {
+		cfg.TickInterval = DefaultTickInterval
+	}
+	if cfg.HoldTimeout == 0 {
+		cfg.HoldTimeout = DefaultHoldTimeout
+	}
+	cfg.Height = clamp(cfg.Height, cfg.LowestHeight, cfg.HighestHeight)
+
+	d := &Desk{
+		cfg:      cfg,
+		height:   cfg.Height,
+		position: float64(cfg.Height),
+		queued:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go d.run()
+	go d.deliver()
+	return d
+}
+
+// Height returns the current height of the simulated desk in millimeters.
+func (d *Desk) Height() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.height
+}
+
+// Preset returns the height in millimeters stored in memory preset n (1-4).
+func (d *Desk) Preset(n int) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if n < 1 || n > len(d.cfg.Presets) {
+		return 0
+	}
+	return d.cfg.Presets[n-1]
+}
+
+// Moving reports whether the simulated desk is currently moving.
+func (d *Desk) Moving() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.moving
+}
+
+// Write accepts a command frame. Frames that are not valid 0xF1 frames are
+// ignored, as a real controller would.
+func (d *Desk) Write(frame []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return ErrClosed
+	}
+
+	f, err := protocol.ParseCommand(frame)
+	if err != nil {
+		return nil
+	}
+	d.handleCommand(f.Type, f.Payload)
+	return nil
+}
+
+// Subscribe registers the handler receiving notifications from the desk.
+func (d *Desk) Subscribe(handler func(buf []byte)) error {
+	d.handlerMu.Lock()
+	defer d.handlerMu.Unlock()
+	d.handler = handler
+	return nil
+}
+
+// Close stops the simulation. Notifications still queued are discarded.
+func (d *Desk) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.closed {
+		d.closed = true
+		close(d.done)
+	}
+	return nil
+}
+
+// handleCommand applies a command to the desk state. Must be called with
+// d.mu held.
+func (d *Desk) handleCommand(command byte, data []byte) {
+	switch command {
+	case protocol.CmdUp:
+		d.moveTo(d.cfg.HighestHeight, true)
+	case protocol.CmdDown:
+		d.moveTo(d.cfg.LowestHeight, true)
+	case protocol.CmdSaveMemory1, protocol.CmdSaveMemory2, protocol.CmdSaveMemory3, protocol.CmdSaveMemory4:
+		n := saveMemoryCommands[command]
+		if n > d.cfg.MemoryPresets {
+			return
+		}
+		d.cfg.Presets[n-1] = d.height
+		d.notifyPreset(n)
+	case protocol.CmdGoToMemory1, protocol.CmdGoToMemory2, protocol.CmdGoToMemory3, protocol.CmdGoToMemory4:
+		n := goToMemoryCommands[command]
+		if n > d.cfg.MemoryPresets {
+			return
+		}
+		if preset := d.cfg.Presets[n-1]; preset != 0 {
+			d.moveTo(preset, d.cfg.MemoryConstantTouchMode)
+		}
+	case protocol.CmdFetchSettings:
+		d.notify(protocol.MsgUnits, d.cfg.Units)
+		d.notify(protocol.MsgMemoryMode, boolByte(d.cfg.MemoryConstantTouchMode))
+		d.notify(protocol.MsgAntiCollision, d.cfg.AntiCollisionSensitivity)
+		for n := 1; n <= d.cfg.MemoryPresets; n++ {
+			d.notifyPreset(n)
+		}
+		d.notifyHeight()
+	case protocol.CmdFetchHeightRange:
+		d.notify(protocol.MsgHeightRange,
+			byte(d.cfg.HighestHeight/256), byte(d.cfg.HighestHeight%256),
+			byte(d.cfg.LowestHeight/256), byte(d.cfg.LowestHeight%256))
+	case protocol.CmdSetUnits:
+		if len(data) != 1 || data[0] > byte(protocol.UnitInches) {
+			return
+		}
+		d.cfg.Units = data[0]
+		d.notify(protocol.MsgUnits, d.cfg.Units)
+	case protocol.CmdSetMemoryMode:
+		if len(data) != 1 || data[0] > 1 {
+			return
+		}
+		d.cfg.MemoryConstantTouchMode = data[0] == 0x01
+		d.notify(protocol.MsgMemoryMode, boolByte(d.cfg.MemoryConstantTouchMode))
+	case protocol.CmdSetAntiCollision:
+		if len(data) != 1 || data[0] < 1 || data[0] > 3 {
+			return
+		}
+		d.cfg.AntiCollisionSensitivity = data[0]
+		d.notify(protocol.MsgAntiCollision, d.cfg.AntiCollisionSensitivity)
+	case protocol.CmdQueryLimits:
+		d.notifyLimits()
+	case protocol.CmdSetMaxLimit:
+		d.cfg.MaxLimit = d.height
+		d.notify(protocol.MsgMaxLimit, byte(d.height/256), byte(d.height%256))
+	case protocol.CmdSetMinLimit:
+		d.cfg.MinLimit = d.height
+		d.notify(protocol.MsgMinLimit, byte(d.height/256), byte(d.height%256))
+	case protocol.CmdClearLimits:
+		d.cfg.MinLimit, d.cfg.MaxLimit = 0, 0
+		d.notifyLimits()
+	case protocol.CmdGoToHeight:
+		if len(data) != 2 {
+			return
+		}
+		target := int(data[0])*256 + int(data[1])
+		lowest, highest := d.bounds()
+		if target < lowest || target > highest {
+			return
+		}
+		d.moveTo(target, true)
+	case protocol.CmdFetchStandTime:
+		d.notifyMinutes(protocol.MsgStandTime, d.cfg.StandingTime)
+	case protocol.CmdFetchAllTime:
+		d.notifyMinutes(protocol.MsgAllTime, d.cfg.TotalTime)
+	case protocol.CmdStop:
+		d.stop()
+	}
+}
+
+// moveTo starts moving the desk towards target. If hold is set, the movement
+// only lasts for HoldTimeout unless the command is repeated.
+func (d *Desk) moveTo(target int, hold bool) {
+	lowest, highest := d.bounds()
+	d.target = clamp(target, lowest, highest)
+	d.moving = d.target != d.height
+	d.holdUntil = time.Time{}
+	if hold {
+		d.holdUntil = time.Now().Add(d.cfg.HoldTimeout)
+	}
+}
+
+// bounds returns the range the desk can move in: the physical range,
+// restricted by the user limits that are set.
+func (d *Desk) bounds() (lowest, highest int) {
+	lowest, highest = d.cfg.LowestHeight, d.cfg.HighestHeight
+	if d.cfg.MinLimit != 0 {
+		lowest = max(lowest, d.cfg.MinLimit)
+	}
+	if d.cfg.MaxLimit != 0 {
+		highest = min(highest, d.cfg.MaxLimit)
+	}
+	return lowest, highest
+}
+
+// stop halts the desk and reports its final height.
+func (d *Desk) stop() {
+	if d.moving {
+		d.moving = false
+		d.position = float64(d.height)
+		d.notifyHeight()
+	}
+}
+
+// run advances the desk position every TickInterval.
+func (d *Desk) run() {
+	ticker := time.NewTicker(d.cfg.TickInterval)
+	defer ticker.Stop()
+
+	step := float64(d.cfg.Speed) * d.cfg.TickInterval.Seconds()
+	for {
+		select {
+		case <-d.done:
+			return
+		case now := <-ticker.C:
+			d.mu.Lock()
+			d.tick(now, step)
+			d.mu.Unlock()
+		}
+	}
+}
+
+// tick moves the desk by step millimeters towards its target. Must be
+// called with d.mu held.
+func (d *Desk) tick(now time.Time, step float64) {
+	if !d.moving {
+		return
+	}
+	if !d.holdUntil.IsZero() && now.After(d.holdUntil) {
+		d.stop()
+		return
+	}
+
+	if d.target > d.height {
+		d.position = min(d.position+step, float64(d.target))
+	} else {
+		d.position = max(d.position-step, float64(d.target))
+	}
+	d.height = int(d.position)
+	if d.height == d.target {
+		d.moving = false
+	}
+	d.notifyHeight()
+}
+
+// deliver passes queued notifications to the subscribed handler. d.mu is
+// not held while the handler runs, so the handler may call back into the
+// desk, e.g. to write a command.
+func (d *Desk) deliver() {
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-d.queued:
+		}
+
+		d.mu.Lock()
+		queue := d.queue
+		d.queue = nil
+		d.mu.Unlock()
+
+		for _, buf := range queue {
+			select {
+			case <-d.done:
+				return
+			default:
+			}
+			d.handlerMu.Lock()
+			handler := d.handler
+			d.handlerMu.Unlock()
+			if handler != nil {
+				handler(buf)
+			}
+		}
+	}
+}
+
+func (d *Desk) notifyHeight() {
+	// Meaning of the last data byte is unknown, real controllers send
+	// varying values.
+	d.notify(protocol.MsgHeight, byte(d.height/256), byte(d.height%256), 0x00)
+}
+
+func (d *Desk) notifyPreset(n int) {
+	preset := d.cfg.Presets[n-1]
+	d.notify(byte(protocol.MsgMemoryPreset1+n-1), byte(preset/256), byte(preset%256))
+}
+
+// notifyMinutes reports a usage counter in minutes. Must be called with
+// d.mu held.
+func (d *Desk) notifyMinutes(dataType byte, t time.Duration) {
+	m := uint32(t / time.Minute)
+	d.notify(dataType, byte(m>>24), byte(m>>16), byte(m>>8), byte(m))
+}
+
+// notifyLimits reports which user limits are set, followed by their
+// heights. Must be called with d.mu held.
+func (d *Desk) notifyLimits() {
+	var flags byte
+	if d.cfg.MaxLimit != 0 {
+		flags |= protocol.LimitMaxSet
+	}
+	if d.cfg.MinLimit != 0 {
+		flags |= protocol.LimitMinSet
+	}
+	d.notify(protocol.MsgLimits, flags)
+	if d.cfg.MaxLimit != 0 {
+		d.notify(protocol.MsgMaxLimit, byte(d.cfg.MaxLimit/256), byte(d.cfg.MaxLimit%256))
+	}
+	if d.cfg.MinLimit != 0 {
+		d.notify(protocol.MsgMinLimit, byte(d.cfg.MinLimit/256), byte(d.cfg.MinLimit%256))
+	}
+}
+
+// notify queues a 0xF2 notification frame for deliver. It never blocks.
+// Must be called with d.mu held.
+func (d *Desk) notify(dataType byte, data ...byte) {
+	if d.closed {
+		return
+	}
+	d.queue = append(d.queue, protocol.Frame{Type: dataType, Payload: data}.EncodeNotification())
+
+	select {
+	case d.queued <- struct{}{}:
+	default:
+	}
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 0x01
+	}
+	return 0x00
+}
+
+func clamp(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
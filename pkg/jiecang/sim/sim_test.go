@@ -0,0 +1,244 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// command builds a 0xF1 command frame.
+func command(dataType byte, data ...byte) []byte {
+	return protocol.Frame{Type: dataType, Payload: data}.Encode()
+}
+
+// notification builds a 0xF2 notification frame.
+func notification(dataType byte, data ...byte) []byte {
+	return protocol.Frame{Type: dataType, Payload: data}.EncodeNotification()
+}
+
+// newTestDesk creates a fast desk and returns a channel receiving its
+// notifications.
+func newTestDesk(t *testing.T, cfg Config) (*Desk, chan []byte) {
+	if cfg.Speed == 0 {
+		cfg.Speed = 1000
+	}
+	if cfg.TickInterval == 0 {
+		cfg.TickInterval = 5 * time.Millisecond
+	}
+	d := New(cfg)
+	t.Cleanup(func() { _ = d.Close() })
+
+	received := make(chan []byte, 1024)
+	require.NoError(t, d.Subscribe(func(buf []byte) { received <- buf }))
+	return d, received
+}
+
+// expect waits for the notification want, skipping any other notification.
+func expect(t *testing.T, received chan []byte, want []byte) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case buf := <-received:
+			if string(buf) == string(want) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("notification %x not received", want)
+		}
+	}
+}
+
+func TestFetchHeightRange(t *testing.T) {
+	d, received := newTestDesk(t, Config{})
+	require.NoError(t, d.Write(command(0x0c)))
+	expect(t, received, notification(0x07, 0x04, 0xf6, 0x02, 0x6c))
+}
+
+func TestFetchSettings(t *testing.T) {
+	d, received := newTestDesk(t, Config{
+		Units:                    0x01,
+		MemoryConstantTouchMode:  true,
+		AntiCollisionSensitivity: 3,
+		Presets:                  [4]int{1100, 730},
+	})
+	require.NoError(t, d.Write(command(0x07)))
+	expect(t, received, notification(0x0e, 0x01))
+	expect(t, received, notification(0x19, 0x01))
+	expect(t, received, notification(0x1d, 0x03))
+	expect(t, received, notification(0x25, 0x04, 0x4c))
+	expect(t, received, notification(0x26, 0x02, 0xda))
+	expect(t, received, notification(0x27, 0x00, 0x00))
+	expect(t, received, notification(0x28, 0x00, 0x00))
+	expect(t, received, notification(0x01, 0x02, 0xee, 0x00))
+}
+
+func TestSetUnits(t *testing.T) {
+	d, received := newTestDesk(t, Config{})
+	require.NoError(t, d.Write(command(0x0e, 0x01)))
+	expect(t, received, notification(0x0e, 0x01))
+	require.NoError(t, d.Write(command(0x07)))
+	expect(t, received, notification(0x0e, 0x01))
+}
+
+func TestSetMemoryMode(t *testing.T) {
+	d, received := newTestDesk(t, Config{})
+	require.NoError(t, d.Write(command(0x19, 0x01)))
+	expect(t, received, notification(0x19, 0x01))
+	require.NoError(t, d.Write(command(0x19, 0x00)))
+	expect(t, received, notification(0x19, 0x00))
+}
+
+func TestSetAntiCollision(t *testing.T) {
+	d, received := newTestDesk(t, Config{AntiCollisionSensitivity: 1})
+	require.NoError(t, d.Write(command(0x1d, 0x03)))
+	expect(t, received, notification(0x1d, 0x03))
+
+	// Out of range levels are ignored
+	require.NoError(t, d.Write(command(0x1d, 0x04)))
+	require.NoError(t, d.Write(command(0x07)))
+	expect(t, received, notification(0x1d, 0x03))
+}
+
+func TestLimits(t *testing.T) {
+	d, received := newTestDesk(t, Config{Height: 1100})
+	require.NoError(t, d.Write(command(0x20)))
+	expect(t, received, notification(0x20, 0x00))
+
+	require.NoError(t, d.Write(command(0x21)))
+	expect(t, received, notification(0x21, 0x04, 0x4c))
+
+	// Desk does not move above the upper limit
+	require.NoError(t, d.Write(command(0x1b, 0x04, 0xb0)))
+	require.NoError(t, d.Write(command(0x01)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1100, d.Height())
+
+	require.NoError(t, d.Write(command(0x20)))
+	expect(t, received, notification(0x20, 0x01))
+	expect(t, received, notification(0x21, 0x04, 0x4c))
+
+	require.NoError(t, d.Write(command(0x23)))
+	expect(t, received, notification(0x20, 0x00))
+}
+
+func TestGoToHeight(t *testing.T) {
+	d, received := newTestDesk(t, Config{})
+	require.NoError(t, d.Write(command(0x1b, 0x03, 0x52)))
+	expect(t, received, notification(0x01, 0x03, 0x52, 0x00))
+	assert.Equal(t, 850, d.Height())
+	assert.False(t, d.Moving())
+}
+
+func TestGoToHeightOutOfRange(t *testing.T) {
+	d, _ := newTestDesk(t, Config{})
+	require.NoError(t, d.Write(command(0x1b, 0x05, 0xdc)))
+	assert.False(t, d.Moving())
+	assert.Equal(t, DefaultHeight, d.Height())
+}
+
+func TestHoldTimeout(t *testing.T) {
+	d, _ := newTestDesk(t, Config{Speed: 100, HoldTimeout: 50 * time.Millisecond})
+	require.NoError(t, d.Write(command(0x01)))
+	assert.True(t, d.Moving())
+
+	// A single tap only moves the desk for HoldTimeout
+	require.Eventually(t, func() bool { return !d.Moving() }, time.Second, 5*time.Millisecond)
+	assert.Greater(t, d.Height(), DefaultHeight)
+	assert.Less(t, d.Height(), DefaultHighestHeight)
+}
+
+func TestStop(t *testing.T) {
+	d, _ := newTestDesk(t, Config{Speed: 10})
+	require.NoError(t, d.Write(command(0x02)))
+	assert.True(t, d.Moving())
+	require.NoError(t, d.Write(command(0x2b)))
+	assert.False(t, d.Moving())
+}
+
+func TestMemoryPresets(t *testing.T) {
+	d, received := newTestDesk(t, Config{Presets: [4]int{1100}})
+
+	require.NoError(t, d.Write(command(0x04)))
+	expect(t, received, notification(0x26, 0x02, 0xee))
+	assert.Equal(t, DefaultHeight, d.Preset(2))
+
+	// One-touch mode moves to the preset without repeating the command
+	require.NoError(t, d.Write(command(0x05)))
+	expect(t, received, notification(0x01, 0x04, 0x4c, 0x00))
+	assert.Equal(t, 1100, d.Height())
+}
+
+func TestThreeMemoryPresets(t *testing.T) {
+	d, received := newTestDesk(t, Config{MemoryPresets: 3})
+	require.NoError(t, d.Write(command(0x26)))
+	require.NoError(t, d.Write(command(0x07)))
+
+	// Memory preset 4 is neither saved nor reported
+	height := notification(0x01, 0x02, 0xee, 0x00)
+	for buf := range received {
+		assert.NotEqual(t, byte(0x28), buf[2])
+		if string(buf) == string(height) {
+			break
+		}
+	}
+	assert.Equal(t, 0, d.Preset(4))
+}
+
+func TestUsageStats(t *testing.T) {
+	d, received := newTestDesk(t, Config{StandingTime: 90 * time.Minute, TotalTime: 300 * time.Hour})
+	require.NoError(t, d.Write(command(0xa2)))
+	expect(t, received, notification(0xa2, 0x00, 0x00, 0x00, 0x5a))
+	require.NoError(t, d.Write(command(0xaa)))
+	expect(t, received, notification(0xaa, 0x00, 0x00, 0x46, 0x50))
+}
+
+func TestInvalidFrameIgnored(t *testing.T) {
+	d, _ := newTestDesk(t, Config{})
+	frame := command(0x01)
+	frame[4]++ // Corrupt checksum
+	require.NoError(t, d.Write(frame))
+	assert.False(t, d.Moving())
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	d := New(Config{})
+	require.NoError(t, d.Close())
+	assert.ErrorIs(t, d.Write(command(0x01)), ErrClosed)
+}
+
+func TestHandlerCallsBack(t *testing.T) {
+	d := New(Config{})
+	t.Cleanup(func() { _ = d.Close() })
+
+	// The handler is stalled while many notifications are queued, then
+	// calls back into the desk
+	release := make(chan struct{})
+	received := make(chan []byte, 1024)
+	require.NoError(t, d.Subscribe(func(buf []byte) {
+		<-release
+		_ = d.Height()
+		received <- buf
+	}))
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i := 0; i < 100; i++ {
+			_ = d.Write(command(0x0c))
+		}
+	}()
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("writes blocked on the handler")
+	}
+
+	close(release)
+	for i := 0; i < 100; i++ {
+		expect(t, received, notification(0x07, 0x04, 0xf6, 0x02, 0x6c))
+	}
+}
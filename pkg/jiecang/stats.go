@@ -0,0 +1,67 @@
+package jiecang
+
+import (
+	"context"
+	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// UsageStats holds the usage counters kept by the controller.
+//
+// The counters are decoded from the stand time and all time replies as
+// minutes, which is reverse-engineered and may not match every controller.
+type UsageStats struct {
+	StandingTime time.Duration // Time spent at standing height
+	TotalTime    time.Duration // Total time the desk was in use
+}
+
+// QueryUsageStats requests the usage statistics of the controller and waits
+// for the answers. Stats is updated as well.
+//
+// Returns an error if the command transmission fails, the controller does
+// not answer (ErrTimeout) or ctx is cancelled. Fails with ErrUnsupported if
+// the profile of the controller does not support statistics, see
+// WithProfile.
+//
+// Example:
+//
+//	stats, err := desk.QueryUsageStats(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("Standing: %s\n", stats.StandingTime)
+func (j *Jiecang) QueryUsageStats(ctx context.Context) (UsageStats, error) {
+	if err := j.supportsStats(); err != nil {
+		return UsageStats{}, err
+	}
+
+	msg, err := j.request(ctx, commands["fetch_stand_time"], func(m protocol.Message) bool {
+		_, ok := m.(protocol.StandTime)
+		return ok
+	})
+	if err != nil {
+		return UsageStats{}, err
+	}
+	standTime := msg.(protocol.StandTime)
+
+	msg, err = j.request(ctx, commands["fetch_all_time"], func(m protocol.Message) bool {
+		_, ok := m.(protocol.AllTime)
+		return ok
+	})
+	if err != nil {
+		return UsageStats{}, err
+	}
+	allTime := msg.(protocol.AllTime)
+
+	return UsageStats{
+		StandingTime: time.Duration(standTime.Minutes) * time.Minute,
+		TotalTime:    time.Duration(allTime.Minutes) * time.Minute,
+	}, nil
+}
+
+// supportsStats returns an error wrapping ErrUnsupported if the controller
+// does not report usage statistics according to its profile.
+func (j *Jiecang) supportsStats() error {
+	return j.supports("usage statistics", func(p Profile) bool { return p.Stats })
+}
@@ -0,0 +1,36 @@
+package jiecang
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+func TestQueryUsageStats(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+
+	expected := UsageStats{StandingTime: 90 * time.Minute, TotalTime: 300 * time.Hour}
+	// Initialization requests the statistics as well
+	assert.Equal(t, expected, j.Stats)
+
+	stats, err := j.QueryUsageStats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expected, stats)
+}
+
+func TestQueryUsageStatsTimeout(t *testing.T) {
+	ft := newFakeTransport()
+	j, err := New(ft)
+	require.NoError(t, err)
+	j.queryTimeout = 10 * time.Millisecond
+
+	delete(ft.responses, protocol.CmdFetchAllTime)
+	_, err = j.QueryUsageStats(context.Background())
+	assert.ErrorIs(t, err, ErrTimeout)
+}
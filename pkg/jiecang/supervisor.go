@@ -0,0 +1,157 @@
+package jiecang
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// This file contains the connection supervisor, which keeps long-lived
+// connections to the controller alive.
+
+const (
+	// defaultHealthInterval is how often the supervisor checks that the
+	// controller still answers.
+	defaultHealthInterval = 15 * time.Second
+
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// Dialer establishes a new link to the controller. It is called by
+// Supervise to replace a link that was lost.
+type Dialer func(ctx context.Context) (Transport, error)
+
+// Supervise watches the connection to the controller and re-establishes it
+// when it is lost, until ctx is done or the desk is disconnected. It blocks,
+// so it is usually run in its own goroutine.
+//
+// The link is checked periodically by querying the height range, and right
+// away when a command cannot be written. The answers to these checks are
+// not sent as FrameReceived events, so that monitoring the desk only shows
+// its own traffic; protocol traces record them like any other. Once the
+// link is considered lost, a Disconnected event is published and dial is
+// called with exponential backoff until a new link is established. The new
+// link replaces the old one, the desk state is queried again like in New,
+// and a Connected event is published.
+//
+// Returns ctx.Err() when ctx is done, or nil once the desk is disconnected.
+//
+// Example:
+//
+//	go desk.Supervise(ctx, jiecang.BLEDialer(adapter, address))
+func (j *Jiecang) Supervise(ctx context.Context, dial Dialer) error {
+	ticker := time.NewTicker(j.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-j.done:
+			return nil
+		case <-ticker.C:
+		case <-j.linkErr:
+		}
+
+		err := j.checkLink(ctx)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		j.logger.Warn("connection lost", "err", err)
+		j.events.publish(Disconnected{Err: err})
+		if err := j.reconnect(ctx, dial); err != nil {
+			if errors.Is(err, errClosed) {
+				return nil
+			}
+			return err
+		}
+		j.logger.Info("connection re-established")
+		j.events.publish(Connected{})
+	}
+}
+
+// errClosed is returned by reconnect when the desk is disconnected.
+var errClosed = errors.New("desk disconnected")
+
+// checkLink returns an error if the controller does not answer. The answer
+// is not published as a FrameReceived event.
+func (j *Jiecang) checkLink(ctx context.Context) error {
+	wt := j.waiters.addQuiet(func(m protocol.Message) bool {
+		_, ok := m.(protocol.HeightRange)
+		return ok
+	})
+	defer j.waiters.remove(wt)
+
+	_, err := j.await(ctx, commands["fetch_height_range"], wt)
+	return err
+}
+
+// reconnect dials a new link with exponential backoff until it succeeds,
+// then attaches it and refreshes the desk state.
+func (j *Jiecang) reconnect(ctx context.Context, dial Dialer) error {
+	backoff := j.minBackoff
+	for {
+		err := j.redial(ctx, dial)
+		if err == nil {
+			return nil
+		}
+		j.logger.Warn("reconnection failed", "err", err, "retry", backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-j.done:
+			return errClosed
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, j.maxBackoff)
+	}
+}
+
+// redial makes a single reconnection attempt.
+func (j *Jiecang) redial(ctx context.Context, dial Dialer) error {
+	t, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	if err := j.attach(t); err != nil {
+		return err
+	}
+	return j.refresh(ctx)
+}
+
+// attach replaces the current link with t and subscribes to it. The old
+// link is closed first, so that its data is not mixed with data from t.
+func (j *Jiecang) attach(t Transport) error {
+	j.transportMu.Lock()
+	defer j.transportMu.Unlock()
+
+	select {
+	case <-j.done:
+		_ = t.Close()
+		return errClosed
+	default:
+	}
+
+	_ = j.transport.Close()
+	j.frames.Reset()
+	j.transport = t
+	if err := t.Subscribe(j.characteristicReceiver); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	// Drain failures of the old link
+	select {
+	case <-j.linkErr:
+	default:
+	}
+	return nil
+}
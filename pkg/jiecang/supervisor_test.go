@@ -0,0 +1,120 @@
+package jiecang
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// newSupervisedDesk returns a desk with fast supervisor timings.
+func newSupervisedDesk(t *testing.T, ft *fakeTransport, opts ...Option) *Jiecang {
+	j, err := New(ft, opts...)
+	require.NoError(t, err)
+	j.queryTimeout = 10 * time.Millisecond
+	j.queryRetries = 0
+	j.healthInterval = 20 * time.Millisecond
+	j.minBackoff = time.Millisecond
+	j.maxBackoff = 5 * time.Millisecond
+	return j
+}
+
+func TestSupervise(t *testing.T) {
+	old := newFakeTransport()
+	j := newSupervisedDesk(t, old)
+	events := j.Subscribe(context.Background())
+
+	// First attempts fail, like a desk that is still powering up
+	var mu sync.Mutex
+	attempts := 0
+	replacement := newFakeTransport()
+	dial := func(ctx context.Context) (Transport, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("device not found")
+		}
+		return replacement, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	supervised := make(chan error, 1)
+	go func() { supervised <- j.Supervise(ctx, dial) }()
+
+	old.fail(errors.New("link lost"))
+	assert.Error(t, j.Up())
+
+	assert.IsType(t, Disconnected{}, nextEvent(t, events))
+	for {
+		if _, ok := nextEvent(t, events).(Connected); ok {
+			break
+		}
+	}
+	assert.True(t, old.closed)
+	mu.Lock()
+	assert.Equal(t, 3, attempts)
+	mu.Unlock()
+
+	// Commands go to the new link
+	require.NoError(t, j.Up())
+	assert.Equal(t, 1, replacement.count(commands["up"].Encode()))
+
+	require.NoError(t, j.Disconnect())
+	assert.NoError(t, <-supervised)
+}
+
+func TestSuperviseHealthy(t *testing.T) {
+	ft := newFakeTransport()
+	j := newSupervisedDesk(t, ft)
+
+	dial := func(ctx context.Context) (Transport, error) {
+		t.Error("unexpected reconnection")
+		return nil, errors.New("unexpected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, j.Supervise(ctx, dial), context.DeadlineExceeded)
+
+	// The link was checked a few times
+	assert.Greater(t, ft.count(commands["fetch_height_range"].Encode()), 2)
+}
+
+func TestSuperviseQuiet(t *testing.T) {
+	ft := newFakeTransport()
+	var trace bytes.Buffer
+	j := newSupervisedDesk(t, ft, WithTrace(&trace))
+	traced := trace.Len()
+	frames := j.SubscribeFrames(context.Background())
+
+	dial := func(ctx context.Context) (Transport, error) {
+		t.Error("unexpected reconnection")
+		return nil, errors.New("unexpected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, j.Supervise(ctx, dial), context.DeadlineExceeded)
+	require.Greater(t, ft.count(commands["fetch_height_range"].Encode()), 2)
+
+	// Link checks are traced, but not sent as frames
+	assert.Greater(t, trace.Len(), traced)
+	select {
+	case e := <-frames:
+		t.Errorf("unexpected event %#v", e)
+	default:
+	}
+
+	// Other queries of the height range still are
+	_, err := j.QueryHeightRange(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, FrameReceived{Raw: reply(0x07, 0x04, 0xf8, 0x02, 0x6c), Message: protocol.HeightRange{Highest: 1272, Lowest: 620}}, nextEvent(t, frames))
+}
@@ -0,0 +1,126 @@
+package jiecang
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// This file contains the height targets, which express the height to move
+// the desk to as an absolute height, a distance from the current height or
+// a percentage of the height range.
+
+// TargetKind is the kind of a Target.
+type TargetKind int
+
+const (
+	TargetAbsolute TargetKind = iota // Height, e.g. "107.5"
+	TargetRelative                   // Distance from the current height, e.g. "+5" or "-3.5"
+	TargetPercent                    // Percentage of the height range, e.g. "75%"
+)
+
+// Target is a height target, see ParseTarget.
+type Target struct {
+	Kind TargetKind
+	// Value is the height or the distance in display units, or the
+	// percentage of the height range.
+	Value float64
+}
+
+// ParseTarget parses a height target: a positive height (e.g. "107.5"), a
+// distance from the current height starting with a sign (e.g. "+5" or
+// "-3.5"), or a percentage of the height range between 0 and 100 (e.g.
+// "75%"). Heights and distances are in display units, the units are applied
+// by ResolveTarget.
+func ParseTarget(s string) (Target, error) {
+	s = strings.TrimSpace(s)
+
+	t := Target{Kind: TargetAbsolute}
+	number := s
+	switch {
+	case strings.HasSuffix(s, "%"):
+		t.Kind, number = TargetPercent, strings.TrimSuffix(s, "%")
+	case strings.HasPrefix(s, "+"), strings.HasPrefix(s, "-"):
+		t.Kind = TargetRelative
+	}
+
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return Target{}, fmt.Errorf("invalid height target %q", s)
+	}
+	t.Value = v
+
+	switch t.Kind {
+	case TargetAbsolute:
+		if v <= 0 {
+			return Target{}, fmt.Errorf("height must be positive")
+		}
+	case TargetRelative:
+		if v == 0 {
+			return Target{}, fmt.Errorf("distance must not be 0")
+		}
+	case TargetPercent:
+		if v < 0 || v > 100 {
+			return Target{}, fmt.Errorf("percentage must be between 0 and 100")
+		}
+	}
+	return t, nil
+}
+
+// String returns t as accepted by ParseTarget.
+func (t Target) String() string {
+	v := strconv.FormatFloat(t.Value, 'f', -1, 64)
+	switch t.Kind {
+	case TargetRelative:
+		if t.Value > 0 {
+			return "+" + v
+		}
+	case TargetPercent:
+		return v + "%"
+	}
+	return v
+}
+
+// ResolveTarget returns the height of t, with heights and distances in u.
+//
+// Distances are added to the current height and percentages are taken of
+// the physical height range (LowestHeight to HighestHeight). Both are
+// clamped to the allowed range (see AllowedRange), so that "+10" moves the
+// desk as high as it goes when less than 10 units are left. Absolute
+// heights are not clamped, GoToHeight validates them.
+//
+// Returns an error if an absolute height is out of the range of Height, see
+// ParseHeight.
+//
+// Example:
+//
+//	t, err := jiecang.ParseTarget("75%")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	h, err := desk.ResolveTarget(t, desk.Units)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	res, err := desk.GoToHeight(ctx, h)
+func (j *Jiecang) ResolveTarget(t Target, u Unit) (Height, error) {
+	j.mu.RLock()
+	current, lowest, highest := j.currentHeight, j.LowestHeight, j.HighestHeight
+	j.mu.RUnlock()
+
+	var target float64
+	switch t.Kind {
+	case TargetRelative:
+		target = float64(current) + u.millimeters(t.Value)
+	case TargetPercent:
+		target = float64(lowest) + float64(int(highest)-int(lowest))*t.Value/100
+	default:
+		return ParseHeight(t.Value, u)
+	}
+
+	// Clamp before converting, distances may exceed the range of Height
+	r := j.AllowedRange()
+	target = math.Round(min(max(target, float64(r.Lowest)), float64(r.Highest)))
+	return Height(target), nil
+}
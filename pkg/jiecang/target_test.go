@@ -0,0 +1,183 @@
+package jiecang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		name        string // Name of the testcase
+		input       string // Input
+		expected    Target // Expected result
+		expectedErr bool   // Whether an error is expected
+	}{
+		{
+			name:     "Absolute height",
+			input:    "107.5",
+			expected: Target{Kind: TargetAbsolute, Value: 107.5},
+		},
+		{
+			name:     "Relative up",
+			input:    "+5",
+			expected: Target{Kind: TargetRelative, Value: 5},
+		},
+		{
+			name:     "Relative down",
+			input:    "-3.5",
+			expected: Target{Kind: TargetRelative, Value: -3.5},
+		},
+		{
+			name:     "Percentage",
+			input:    "75%",
+			expected: Target{Kind: TargetPercent, Value: 75},
+		},
+		{
+			name:        "Zero height",
+			input:       "0",
+			expectedErr: true,
+		},
+		{
+			name:        "Zero distance",
+			input:       "+0",
+			expectedErr: true,
+		},
+		{
+			name:        "Percentage above 100",
+			input:       "101%",
+			expectedErr: true,
+		},
+		{
+			name:        "Negative percentage",
+			input:       "-5%",
+			expectedErr: true,
+		},
+		{
+			name:        "Not a number",
+			input:       "high",
+			expectedErr: true,
+		},
+		{
+			name:        "Infinity",
+			input:       "+Inf",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		target, err := ParseTarget(test.input)
+		if test.expectedErr {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, target, test.name)
+		assert.Equal(t, test.input, target.String(), test.name)
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	tests := []struct {
+		name        string // Name of the testcase
+		input       string // Input, parsed with ParseTarget
+		unit        Unit   // Units of the input
+		limits      Limits // User limits of the desk
+		expected    Height // Expected result
+		expectedErr bool   // Whether an error is expected
+	}{
+		{
+			name:     "Absolute height",
+			input:    "107.5",
+			unit:     UnitCentimeters,
+			expected: 1075,
+		},
+		{
+			name:     "Absolute height out of range is not clamped",
+			input:    "200",
+			unit:     UnitCentimeters,
+			expected: 2000,
+		},
+		{
+			name:        "Absolute height out of the range of Height",
+			input:       "6628.6",
+			unit:        UnitCentimeters,
+			expectedErr: true,
+		},
+		{
+			name:     "Relative up",
+			input:    "+5",
+			unit:     UnitCentimeters,
+			expected: 850,
+		},
+		{
+			name:     "Relative down in inches",
+			input:    "-2",
+			unit:     UnitInches,
+			expected: 749,
+		},
+		{
+			name:     "Relative clamped to highest height",
+			input:    "+100",
+			unit:     UnitCentimeters,
+			expected: 1272,
+		},
+		{
+			name:     "Relative beyond the range of Height",
+			input:    "+6560",
+			unit:     UnitCentimeters,
+			expected: 1272,
+		},
+		{
+			name:     "Relative below 0",
+			input:    "-6560",
+			unit:     UnitInches,
+			expected: 620,
+		},
+		{
+			name:     "Relative clamped to lower limit",
+			input:    "-10",
+			unit:     UnitCentimeters,
+			limits:   Limits{Min: 750},
+			expected: 750,
+		},
+		{
+			name:     "Percentage",
+			input:    "50%",
+			unit:     UnitCentimeters,
+			expected: 946,
+		},
+		{
+			name:     "Percentage of the physical range",
+			input:    "0%",
+			unit:     UnitCentimeters,
+			expected: 620,
+		},
+		{
+			name:     "Percentage clamped to upper limit",
+			input:    "100%",
+			unit:     UnitInches,
+			limits:   Limits{Max: 1100},
+			expected: 1100,
+		},
+	}
+
+	for _, test := range tests {
+		ft := newFakeTransport()
+		j, err := New(ft)
+		require.NoError(t, err, test.name)
+		ft.notify(heightReply(800))
+		j.UserLimits = test.limits
+
+		target, err := ParseTarget(test.input)
+		require.NoError(t, err, test.name)
+		h, err := j.ResolveTarget(target, test.unit)
+		if test.expectedErr {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, h, test.name)
+	}
+}
@@ -0,0 +1,174 @@
+package jiecang
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	tcpDialTimeout         = 5 * time.Second
+	tcpMinReconnectBackoff = 500 * time.Millisecond
+	tcpMaxReconnectBackoff = 30 * time.Second
+)
+
+// ErrNotConnected is returned when writing to a transport whose link is
+// currently down.
+var ErrNotConnected = errors.New("not connected")
+
+// TCPTransport is a Transport that talks to the controller's UART through a
+// raw TCP serial bridge, such as ser2net or an ESP32 wired to the controller.
+//
+// Frames are exchanged unmodified over the TCP stream. When the connection
+// drops, it is re-established in the background with exponential backoff;
+// writes fail with ErrNotConnected in the meantime.
+type TCPTransport struct {
+	addr string
+
+	mu      sync.Mutex
+	conn    net.Conn // nil while disconnected
+	handler func(buf []byte)
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	done      chan struct{}
+	wg        sync.WaitGroup // Tracks the reader goroutine
+	closeOnce sync.Once
+}
+
+// NewTCPTransport connects to the serial bridge listening at addr (host:port).
+//
+// Returns an error if the initial connection fails.
+func NewTCPTransport(addr string) (*TCPTransport, error) {
+	t := &TCPTransport{
+		addr:       addr,
+		minBackoff: tcpMinReconnectBackoff,
+		maxBackoff: tcpMaxReconnectBackoff,
+		done:       make(chan struct{}),
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, tcpDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	t.conn = conn
+	return t, nil
+}
+
+// Write sends frame over the current connection. If the write fails, the
+// connection is dropped and re-established in the background.
+func (t *TCPTransport) Write(frame []byte) error {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+
+	if conn == nil {
+		return ErrNotConnected
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.drop(conn)
+		return err
+	}
+	return nil
+}
+
+// Subscribe starts reading from the connection in the background and
+// forwards everything received to handler, reconnecting whenever the
+// connection drops, until the transport is closed.
+func (t *TCPTransport) Subscribe(handler func(buf []byte)) error {
+	t.mu.Lock()
+	t.handler = handler
+	t.mu.Unlock()
+
+	t.wg.Add(1)
+	go t.run()
+	return nil
+}
+
+// Close closes the connection and stops reconnecting.
+func (t *TCPTransport) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		close(t.done)
+		t.mu.Lock()
+		if t.conn != nil {
+			err = t.conn.Close()
+			t.conn = nil
+		}
+		t.mu.Unlock()
+		t.wg.Wait()
+	})
+	return err
+}
+
+// run reads from the connection until it drops, then reconnects.
+func (t *TCPTransport) run() {
+	defer t.wg.Done()
+
+	buf := make([]byte, 256)
+	for {
+		t.mu.Lock()
+		conn, handler := t.conn, t.handler
+		t.mu.Unlock()
+
+		if conn == nil {
+			if !t.reconnect() {
+				return
+			}
+			continue
+		}
+
+		n, err := conn.Read(buf)
+		if n > 0 {
+			handler(append([]byte(nil), buf[:n]...))
+		}
+		if err != nil {
+			t.drop(conn)
+		}
+	}
+}
+
+// reconnect dials addr with exponential backoff until it succeeds or the
+// transport is closed. Returns false if the transport was closed.
+func (t *TCPTransport) reconnect() bool {
+	backoff := t.minBackoff
+	for {
+		select {
+		case <-t.done:
+			return false
+		case <-time.After(backoff):
+		}
+
+		conn, err := net.DialTimeout("tcp", t.addr, tcpDialTimeout)
+		if err != nil {
+			backoff = min(2*backoff, t.maxBackoff)
+			continue
+		}
+
+		t.mu.Lock()
+		select {
+		case <-t.done:
+			t.mu.Unlock()
+			_ = conn.Close()
+			return false
+		default:
+		}
+		t.conn = conn
+		t.mu.Unlock()
+		return true
+	}
+}
+
+// drop closes conn, if it is still the current connection, so that the
+// reader reconnects.
+func (t *TCPTransport) drop(conn net.Conn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == conn {
+		_ = conn.Close()
+		t.conn = nil
+	}
+}
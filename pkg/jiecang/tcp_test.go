@@ -0,0 +1,105 @@
+package jiecang
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acceptController accepts the next connection on l, playing the role of
+// the controller side of a serial bridge.
+func acceptController(t *testing.T, l net.Listener) net.Conn {
+	t.Helper()
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// readFrame reads a single frame written by the transport.
+func readFrame(t *testing.T, conn net.Conn) []byte {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return buf[:n]
+}
+
+func TestTCPTransport(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	tt, err := NewTCPTransport(l.Addr().String())
+	require.NoError(t, err)
+	tt.minBackoff = 10 * time.Millisecond
+	defer func() { _ = tt.Close() }()
+
+	received := make(chan []byte, 16)
+	require.NoError(t, tt.Subscribe(func(buf []byte) { received <- buf }))
+
+	conn := acceptController(t, l)
+	require.NoError(t, tt.Write(commands["fetch_height"].Encode()))
+	assert.Equal(t, commands["fetch_height"].Encode(), readFrame(t, conn))
+
+	_, err = conn.Write(heightReply(820))
+	require.NoError(t, err)
+	select {
+	case buf := <-received:
+		assert.Equal(t, heightReply(820), buf)
+	case <-time.After(time.Second):
+		t.Fatal("notification not received")
+	}
+
+	// Drop the connection, the transport should reconnect
+	require.NoError(t, conn.Close())
+	conn = acceptController(t, l)
+
+	_, err = conn.Write(heightReply(900))
+	require.NoError(t, err)
+	select {
+	case buf := <-received:
+		assert.Equal(t, heightReply(900), buf)
+	case <-time.After(time.Second):
+		t.Fatal("notification not received after reconnect")
+	}
+
+	require.Eventually(t, func() bool {
+		return tt.Write(commands["fetch_height"].Encode()) == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, commands["fetch_height"].Encode(), readFrame(t, conn))
+}
+
+func TestTCPTransportNotConnected(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	tt, err := NewTCPTransport(l.Addr().String())
+	require.NoError(t, err)
+	tt.minBackoff = time.Hour
+	require.NoError(t, tt.Subscribe(func(buf []byte) {}))
+
+	// Stop the bridge, writes fail until it comes back
+	conn := acceptController(t, l)
+	require.NoError(t, l.Close())
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		return tt.Write(commands["fetch_height"].Encode()) == ErrNotConnected
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, tt.Close())
+}
+
+func TestTCPTransportConnectionRefused(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	_, err = NewTCPTransport(addr)
+	assert.Error(t, err)
+}
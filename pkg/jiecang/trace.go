@@ -0,0 +1,277 @@
+package jiecang
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// This file contains the protocol trace facility. A trace records every
+// command written to the controller and everything received from it, as
+// JSON lines:
+//
+//	{"time":"2025-06-01T10:00:00.1Z","dir":"tx","data":"f1f1070007077e"}
+//	{"time":"2025-06-01T10:00:00.15Z","dir":"rx","data":"f2f20103033707457e"}
+//
+// Traces are recorded with WithTrace and replayed with ReplayTransport.
+
+// TraceDirection is the direction of a TraceEntry.
+type TraceDirection string
+
+const (
+	TraceSent     TraceDirection = "tx" // Command written to the controller
+	TraceReceived TraceDirection = "rx" // Data received from the controller
+)
+
+// TraceEntry is a single record of a protocol trace.
+type TraceEntry struct {
+	Time      time.Time      `json:"time"`
+	Direction TraceDirection `json:"dir"`
+	Data      string         `json:"data"` // Hex encoded bytes
+}
+
+// Bytes returns the decoded data of the entry.
+func (e TraceEntry) Bytes() ([]byte, error) {
+	return hex.DecodeString(e.Data)
+}
+
+// ReadTrace reads a trace recorded with WithTrace.
+//
+// Returns an error if a line is not a valid trace entry.
+func ReadTrace(r io.Reader) ([]TraceEntry, error) {
+	var entries []TraceEntry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e TraceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("trace line %d: %w", line, err)
+		}
+		if e.Direction != TraceSent && e.Direction != TraceReceived {
+			return nil, fmt.Errorf("trace line %d: unknown direction %q", line, e.Direction)
+		}
+		if _, err := e.Bytes(); err != nil {
+			return nil, fmt.Errorf("trace line %d: invalid data: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+	return entries, nil
+}
+
+// WithTrace records the communication with the controller to w, see
+// ReadTrace for the format. The trace spans reconnections.
+func WithTrace(w io.Writer) Option {
+	return func(j *Jiecang) {
+		j.tracer = &tracer{enc: json.NewEncoder(w)}
+	}
+}
+
+// tracer writes trace entries.
+type tracer struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	failed bool // Whether writing failed, no more entries are written
+}
+
+// trace records data in the trace, if any. Tracing stops at the first write
+// error, which is logged.
+func (j *Jiecang) trace(dir TraceDirection, data []byte) {
+	t := j.tracer
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.failed {
+		return
+	}
+	e := TraceEntry{Time: time.Now(), Direction: dir, Data: hex.EncodeToString(data)}
+	if err := t.enc.Encode(e); err != nil {
+		t.failed = true
+		j.logger.Warn("failed to write trace", "err", err)
+	}
+}
+
+// replayEntry is a trace entry ready to be replayed.
+type replayEntry struct {
+	direction TraceDirection
+	data      []byte
+	delay     time.Duration // Time since the previous entry
+}
+
+// ReplayTransport is a Transport that plays back a recorded trace.
+//
+// Data received in the trace is handed over at the recorded pace, anchored
+// to the commands: writing a command of the trace plays the data received
+// after it, up to the next command. Data received before the first command
+// is played on Subscribe. Writing a command found further in the trace
+// skips ahead, immediately playing the data received in between. Commands
+// not found in the rest of the trace are ignored and reported by Unexpected.
+type ReplayTransport struct {
+	mu         sync.Mutex
+	entries    []replayEntry
+	next       int           // Index of the next entry of the trace
+	pending    []replayEntry // Entries waiting to be played
+	unexpected [][]byte      // Writes not found in the trace
+	handler    func(buf []byte)
+
+	wake      chan struct{} // Signals new pending entries to the player
+	finished  chan struct{} // Closed once all the data received was played
+	done      chan struct{} // Closed by Close
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewReplayTransport returns a transport playing back entries.
+//
+// Returns an error if an entry has invalid data.
+func NewReplayTransport(entries []TraceEntry) (*ReplayTransport, error) {
+	t := &ReplayTransport{
+		wake:     make(chan struct{}, 1),
+		finished: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for i, e := range entries {
+		data, err := e.Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("trace entry %d: invalid data: %w", i+1, err)
+		}
+		var delay time.Duration
+		if i > 0 {
+			delay = max(e.Time.Sub(entries[i-1].Time), 0)
+		}
+		t.entries = append(t.entries, replayEntry{direction: e.Direction, data: data, delay: delay})
+	}
+	return t, nil
+}
+
+// Write plays the data received after frame in the trace.
+func (t *ReplayTransport) Write(frame []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	found := -1
+	for i := t.next; i < len(t.entries); i++ {
+		if e := t.entries[i]; e.direction == TraceSent && string(e.data) == string(frame) {
+			found = i
+			break
+		}
+	}
+	if found < 0 {
+		t.unexpected = append(t.unexpected, append([]byte(nil), frame...))
+		return nil
+	}
+
+	// Skipped entries are played right away
+	for _, e := range t.entries[t.next:found] {
+		if e.direction == TraceReceived {
+			e.delay = 0
+			t.pending = append(t.pending, e)
+		}
+	}
+	t.next = found + 1
+	t.queueReceived()
+	return nil
+}
+
+// Subscribe plays the data received before the first command in the trace.
+func (t *ReplayTransport) Subscribe(handler func(buf []byte)) error {
+	t.mu.Lock()
+	t.handler = handler
+	t.queueReceived()
+	t.mu.Unlock()
+
+	t.wg.Add(1)
+	go t.play()
+	return nil
+}
+
+// Close stops the playback.
+func (t *ReplayTransport) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+		t.wg.Wait()
+	})
+	return nil
+}
+
+// Finished returns a channel closed once all the data received in the trace
+// was played.
+func (t *ReplayTransport) Finished() <-chan struct{} {
+	return t.finished
+}
+
+// Unexpected returns the commands written that were not found in the trace.
+func (t *ReplayTransport) Unexpected() [][]byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([][]byte(nil), t.unexpected...)
+}
+
+// queueReceived queues the data received up to the next command for
+// playback. Must be called with mu held.
+func (t *ReplayTransport) queueReceived() {
+	for t.next < len(t.entries) && t.entries[t.next].direction == TraceReceived {
+		t.pending = append(t.pending, t.entries[t.next])
+		t.next++
+	}
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// receivedAhead reports whether data received remains in the rest of the
+// trace. Must be called with mu held.
+func (t *ReplayTransport) receivedAhead() bool {
+	for _, e := range t.entries[t.next:] {
+		if e.direction == TraceReceived {
+			return true
+		}
+	}
+	return false
+}
+
+// play hands over the pending entries to the handler, one at a time, until
+// the trace is over or the transport is closed.
+func (t *ReplayTransport) play() {
+	defer t.wg.Done()
+
+	for {
+		t.mu.Lock()
+		if len(t.pending) == 0 {
+			if !t.receivedAhead() {
+				close(t.finished)
+				t.mu.Unlock()
+				return
+			}
+			t.mu.Unlock()
+			select {
+			case <-t.done:
+				return
+			case <-t.wake:
+			}
+			continue
+		}
+		e, handler := t.pending[0], t.handler
+		t.pending = t.pending[1:]
+		t.mu.Unlock()
+
+		select {
+		case <-t.done:
+			return
+		case <-time.After(e.delay):
+		}
+		handler(e.data)
+	}
+}
@@ -0,0 +1,138 @@
+package jiecang
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTrace(t *testing.T) {
+	var buf bytes.Buffer
+	ft := newFakeTransport()
+	j, err := New(ft, WithTrace(&buf))
+	require.NoError(t, err)
+	require.NoError(t, j.Up())
+
+	entries, err := ReadTrace(&buf)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	// Initial query and its answer
+	assert.Equal(t, TraceSent, entries[0].Direction)
+	assert.Equal(t, "f1f10700077e", entries[0].Data)
+	assert.Equal(t, TraceReceived, entries[1].Direction)
+
+	last := entries[len(entries)-1]
+	assert.Equal(t, TraceSent, last.Direction)
+	assert.Equal(t, "f1f10100017e", last.Data)
+	assert.False(t, last.Time.Before(entries[0].Time))
+}
+
+func TestReadTrace(t *testing.T) {
+	tests := []struct {
+		name            string // Name of the testcase
+		input           string // Input
+		expectedEntries int    // Expected number of entries
+		expectedError   bool   // Whether an error is expected
+	}{
+		{
+			name: "Valid trace",
+			input: `{"time":"2025-06-01T10:00:00Z","dir":"tx","data":"f1f10700077e"}
+
+{"time":"2025-06-01T10:00:00.1Z","dir":"rx","data":"f2f20103033707457e"}`,
+			expectedEntries: 2,
+		},
+		{
+			name:          "Invalid JSON",
+			input:         `{"time":`,
+			expectedError: true,
+		},
+		{
+			name:          "Unknown direction",
+			input:         `{"time":"2025-06-01T10:00:00Z","dir":"up","data":"f1f10700077e"}`,
+			expectedError: true,
+		},
+		{
+			name:          "Invalid data",
+			input:         `{"time":"2025-06-01T10:00:00Z","dir":"tx","data":"f1f1zz"}`,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		entries, err := ReadTrace(strings.NewReader(test.input))
+		if test.expectedError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		assert.Len(t, entries, test.expectedEntries, test.name)
+	}
+}
+
+func TestReplayTransport(t *testing.T) {
+	start := time.Now()
+	entries := []TraceEntry{
+		{Time: start, Direction: TraceReceived, Data: "01"},
+		{Time: start, Direction: TraceSent, Data: "a0"},
+		{Time: start.Add(10 * time.Millisecond), Direction: TraceReceived, Data: "02"},
+		{Time: start, Direction: TraceSent, Data: "b0"},
+		{Time: start, Direction: TraceReceived, Data: "03"},
+		{Time: start, Direction: TraceSent, Data: "c0"},
+		{Time: start, Direction: TraceReceived, Data: "04"},
+	}
+	rt, err := NewReplayTransport(entries)
+	require.NoError(t, err)
+	defer func() { _ = rt.Close() }()
+
+	received := make(chan byte, len(entries))
+	require.NoError(t, rt.Subscribe(func(buf []byte) { received <- buf[0] }))
+	assert.Equal(t, byte(0x01), <-received)
+
+	require.NoError(t, rt.Write([]byte{0xa0}))
+	assert.Equal(t, byte(0x02), <-received)
+
+	// Unknown commands are ignored, commands further in the trace skip ahead
+	require.NoError(t, rt.Write([]byte{0xff}))
+	require.NoError(t, rt.Write([]byte{0xc0}))
+	assert.Equal(t, byte(0x03), <-received)
+	assert.Equal(t, byte(0x04), <-received)
+
+	select {
+	case <-rt.Finished():
+	case <-time.After(time.Second):
+		t.Fatal("trace not finished")
+	}
+	assert.Equal(t, [][]byte{{0xff}}, rt.Unexpected())
+}
+
+// TestReplayGoToHeight replays a recorded session moving the desk from
+// 80 cm to 90 cm.
+func TestReplayGoToHeight(t *testing.T) {
+	f, err := os.Open("testdata/goto_height.jsonl")
+	require.NoError(t, err)
+	defer f.Close()
+	entries, err := ReadTrace(f)
+	require.NoError(t, err)
+
+	rt, err := NewReplayTransport(entries)
+	require.NoError(t, err)
+	j, err := New(rt)
+	require.NoError(t, err)
+	defer func() { _ = j.Disconnect() }()
+
+	assert.Equal(t, Height(1100), j.presets["memory1"])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := j.GoToHeight(ctx, 900)
+	require.NoError(t, err)
+	assert.Equal(t, Arrived, res.Outcome)
+	assert.Equal(t, Height(900), res.Height)
+}
@@ -0,0 +1,21 @@
+package jiecang
+
+// Transport is a bidirectional link to a Jiecang controller.
+//
+// Implementations carry raw protocol frames between the Jiecang type and the
+// controller, without interpreting them. This decouples the protocol logic
+// from the physical link (BLE, serial, ...) and allows it to be exercised
+// without real hardware.
+type Transport interface {
+	// Write sends a single command frame to the controller.
+	Write(frame []byte) error
+
+	// Subscribe registers handler to be called with the bytes received
+	// from the controller. A single call may contain zero, one or more
+	// frames, or only part of a frame. handler is never called
+	// concurrently. Subscribe is called once, before any command is written.
+	Subscribe(handler func(buf []byte)) error
+
+	// Close releases the underlying link.
+	Close() error
+}
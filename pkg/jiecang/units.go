@@ -0,0 +1,143 @@
+package jiecang
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tzermias/deskctl/pkg/jiecang/protocol"
+)
+
+// Height is a desk height in millimeters, the resolution used by the
+// controller.
+//
+// Use Centimeters or Inches to build a Height from other units, or the
+// Millimeter and Centimeter constants:
+//
+//	desk.GoToHeight(ctx, 107*jiecang.Centimeter+5*jiecang.Millimeter)
+type Height uint16
+
+const (
+	Millimeter Height = 1
+	Centimeter Height = 10
+)
+
+// mmPerInch is the number of millimeters in an inch.
+const mmPerInch = 25.4
+
+// Centimeters returns the Height of cm centimeters, rounded to the nearest
+// millimeter. cm must be within the range of Height, use ParseHeight for
+// values given by users.
+func Centimeters(cm float64) Height {
+	return Height(math.Round(cm * 10))
+}
+
+// Inches returns the Height of in inches, rounded to the nearest
+// millimeter. in must be within the range of Height, use ParseHeight for
+// values given by users.
+func Inches(in float64) Height {
+	return Height(math.Round(in * mmPerInch))
+}
+
+// Millimeters returns h in millimeters.
+func (h Height) Millimeters() int {
+	return int(h)
+}
+
+// Centimeters returns h in centimeters.
+func (h Height) Centimeters() float64 {
+	return float64(h) / 10
+}
+
+// Inches returns h in inches.
+func (h Height) Inches() float64 {
+	return float64(h) / mmPerInch
+}
+
+// String formats h in centimeters, with millimeter precision only when
+// needed (e.g. "107 cm", "107.5 cm").
+func (h Height) String() string {
+	return strconv.FormatFloat(h.Centimeters(), 'f', -1, 64) + " cm"
+}
+
+// InchesString formats h in inches, with one decimal (e.g. "42.1 in").
+func (h Height) InchesString() string {
+	return fmt.Sprintf("%.1f in", h.Inches())
+}
+
+// distance returns the absolute difference between h and other.
+func (h Height) distance(other Height) Height {
+	if h > other {
+		return h - other
+	}
+	return other - h
+}
+
+// Unit is a display unit of the controller.
+type Unit uint8
+
+const (
+	UnitCentimeters = Unit(protocol.UnitCentimeters)
+	UnitInches      = Unit(protocol.UnitInches)
+)
+
+// ParseUnit parses the name of a unit: "cm" or "in".
+func ParseUnit(s string) (Unit, error) {
+	switch strings.ToLower(s) {
+	case "cm", "centimeters":
+		return UnitCentimeters, nil
+	case "in", "inches":
+		return UnitInches, nil
+	}
+	return 0, fmt.Errorf("unknown unit %q (valid: cm, in)", s)
+}
+
+// String returns the symbol of u ("cm" or "in").
+func (u Unit) String() string {
+	switch u {
+	case UnitCentimeters:
+		return "cm"
+	case UnitInches:
+		return "in"
+	}
+	return fmt.Sprintf("Unit(%d)", uint8(u))
+}
+
+// Height returns the Height of value expressed in u. value must be within
+// the range of Height, see ParseHeight.
+func (u Unit) Height(value float64) Height {
+	if u == UnitInches {
+		return Inches(value)
+	}
+	return Centimeters(value)
+}
+
+// millimeters returns value expressed in u in millimeters, without rounding.
+func (u Unit) millimeters(value float64) float64 {
+	if u == UnitInches {
+		return value * mmPerInch
+	}
+	return value * 10
+}
+
+// ParseHeight returns the Height of value expressed in u, rounded to the
+// nearest millimeter.
+//
+// Returns an error if value is negative, not a number or higher than the
+// highest Height (65535 mm), which would otherwise wrap around.
+func ParseHeight(value float64, u Unit) (Height, error) {
+	mm := math.Round(u.millimeters(value))
+	if math.IsNaN(mm) || mm < 0 || mm > math.MaxUint16 {
+		return 0, fmt.Errorf("height %v %s is out of range", value, u)
+	}
+	return Height(mm), nil
+}
+
+// Format formats h in u (e.g. "107.5 cm", "42.3 in").
+func (h Height) Format(u Unit) string {
+	if u == UnitInches {
+		return h.InchesString()
+	}
+	return h.String()
+}
@@ -0,0 +1,118 @@
+package jiecang
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeight(t *testing.T) {
+	tests := []struct {
+		name               string  // Name of the testcase
+		input              Height  // Input
+		expectedString     string  // Expected result of String
+		expectedCentimeter float64 // Expected result of Centimeters
+		expectedInches     string  // Expected result of InchesString
+	}{
+		{
+			name:               "Whole centimeters",
+			input:              1070,
+			expectedString:     "107 cm",
+			expectedCentimeter: 107,
+			expectedInches:     "42.1 in",
+		},
+		{
+			name:               "Millimeter precision",
+			input:              1075,
+			expectedString:     "107.5 cm",
+			expectedCentimeter: 107.5,
+			expectedInches:     "42.3 in",
+		},
+		{
+			name:               "Zero",
+			input:              0,
+			expectedString:     "0 cm",
+			expectedCentimeter: 0,
+			expectedInches:     "0.0 in",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedString, test.input.String(), test.name)
+		assert.Equal(t, test.expectedCentimeter, test.input.Centimeters(), test.name)
+		assert.Equal(t, test.expectedInches, test.input.InchesString(), test.name)
+	}
+}
+
+func TestHeightConversions(t *testing.T) {
+	assert.Equal(t, Height(1075), Centimeters(107.5))
+	assert.Equal(t, Height(1075), Centimeters(107.52))
+	assert.Equal(t, Height(1070), 107*Centimeter)
+	assert.Equal(t, Height(1067), Inches(42))
+	assert.Equal(t, 1067, Inches(42).Millimeters())
+	assert.Equal(t, Height(3), Height(1070).distance(1073))
+	assert.Equal(t, Height(3), Height(1073).distance(1070))
+}
+
+func TestParseUnit(t *testing.T) {
+	tests := []struct {
+		name     string // Name of the testcase
+		input    string // Input
+		expected Unit   // Expected result of function
+		err      bool   // Whether an error is expected
+	}{
+		{name: "Centimeters", input: "cm", expected: UnitCentimeters},
+		{name: "Inches", input: "in", expected: UnitInches},
+		{name: "Long name", input: "Inches", expected: UnitInches},
+		{name: "Unknown unit", input: "ft", err: true},
+	}
+
+	for _, test := range tests {
+		u, err := ParseUnit(test.input)
+		if test.err {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, u, test.name)
+	}
+}
+
+func TestParseHeight(t *testing.T) {
+	tests := []struct {
+		name     string  // Name of the testcase
+		input    float64 // Input
+		unit     Unit    // Units of the input
+		expected Height  // Expected result
+		err      bool    // Whether an error is expected
+	}{
+		{name: "Centimeters", input: 107.5, unit: UnitCentimeters, expected: 1075},
+		{name: "Inches", input: 42, unit: UnitInches, expected: 1067},
+		{name: "Highest height", input: 6553.5, unit: UnitCentimeters, expected: 65535},
+		{name: "Zero", input: 0, unit: UnitCentimeters, expected: 0},
+		{name: "Wraps around to 0", input: 6553.6, unit: UnitCentimeters, err: true},
+		{name: "Wraps around to 75 cm", input: 6628.6, unit: UnitCentimeters, err: true},
+		{name: "Too high in inches", input: 2600, unit: UnitInches, err: true},
+		{name: "Negative", input: -1, unit: UnitCentimeters, err: true},
+		{name: "Not a number", input: math.NaN(), unit: UnitCentimeters, err: true},
+		{name: "Infinity", input: math.Inf(1), unit: UnitInches, err: true},
+	}
+
+	for _, test := range tests {
+		h, err := ParseHeight(test.input, test.unit)
+		if test.err {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, h, test.name)
+	}
+}
+
+func TestUnitHeight(t *testing.T) {
+	assert.Equal(t, Height(1075), UnitCentimeters.Height(107.5))
+	assert.Equal(t, Height(1067), UnitInches.Height(42))
+	assert.Equal(t, "42.3 in", Height(1075).Format(UnitInches))
+	assert.Equal(t, "107.5 cm", Height(1075).Format(UnitCentimeters))
+}